@@ -0,0 +1,38 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsJSONNull(t *testing.T) {
+	assert.True(t, IsJSONNull([]byte("null")))
+	assert.True(t, IsJSONNull([]byte(" null\n")))
+	assert.False(t, IsJSONNull([]byte(`"null"`)))
+	assert.False(t, IsJSONNull([]byte("[]")))
+}
+
+func TestGob(t *testing.T) {
+	data, err := GobEncode([]int{1, 2, 3})
+	require.NoError(t, err)
+	var vals []int
+	require.NoError(t, GobDecode(data, &vals))
+	assert.Equal(t, []int{1, 2, 3}, vals)
+	assert.Error(t, GobDecode([]byte("invalid"), &vals))
+}
@@ -24,6 +24,11 @@ func NewErrIndexOutOfRange(length int, index int) error {
 	return fmt.Errorf("ekit: 下标超出范围，长度 %d, 下标 %d", length, index)
 }
 
+// NewErrInvalidRange 创建一个代表范围 [from, to) 不合法的错误
+func NewErrInvalidRange(length int, from int, to int) error {
+	return fmt.Errorf("ekit: 无效的范围 [%d, %d)，长度 %d", from, to, length)
+}
+
 // NewErrInvalidType 创建一个代表类型转换失败的错误
 func NewErrInvalidType(want string, got any) error {
 	return fmt.Errorf("ekit: 类型转换失败，预期类型:%s, 实际值:%#v", want, got)
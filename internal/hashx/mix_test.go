@@ -0,0 +1,37 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hashx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMix64(t *testing.T) {
+	assert.Equal(t, uint64(0), Mix64(0))
+	// 连续的整数打散之后不会冲突，并且低位也不会连续
+	seen := make(map[uint64]struct{}, 1000)
+	lowBits := make(map[uint64]int, 16)
+	for i := uint64(0); i < 1000; i++ {
+		h := Mix64(i)
+		seen[h] = struct{}{}
+		lowBits[h&15]++
+	}
+	assert.Equal(t, 1000, len(seen))
+	for _, cnt := range lowBits {
+		assert.Greater(t, cnt, 30)
+	}
+}
@@ -34,6 +34,9 @@ const (
 type skipListNode[T any] struct {
 	Val     T
 	Forward []*skipListNode[T]
+	// Span[i] 是第 i 层从当前结点到 Forward[i] 跨过了多少个第 1 层的结点，用于计算排名
+	// Forward[i] 为 nil 的时候，Span[i] 是当前结点到末尾的距离
+	Span []int
 }
 
 type SkipList[T any] struct {
@@ -44,7 +47,7 @@ type SkipList[T any] struct {
 }
 
 func newSkipListNode[T any](Val T, level int) *skipListNode[T] {
-	return &skipListNode[T]{Val, make([]*skipListNode[T], level)}
+	return &skipListNode[T]{Val, make([]*skipListNode[T], level), make([]int, level)}
 }
 
 func (sl *SkipList[T]) AsSlice() []T {
@@ -66,10 +69,9 @@ func NewSkipListFromSlice[T any](slice []T, compare ekit.Comparator[T]) *SkipLis
 }
 
 func NewSkipList[T any](compare ekit.Comparator[T]) *SkipList[T] {
+	var zero T
 	return &SkipList[T]{
-		header: &skipListNode[T]{
-			Forward: make([]*skipListNode[T], MaxLevel),
-		},
+		header:  newSkipListNode[T](zero, MaxLevel),
 		level:   1,
 		compare: compare,
 	}
@@ -108,11 +110,25 @@ func (sl *SkipList[T]) traverse(Val T, level int) (*skipListNode[T], []*skipList
 }
 
 func (sl *SkipList[T]) Insert(Val T) {
-	_, update := sl.traverse(Val, sl.level)
+	update := make([]*skipListNode[T], MaxLevel)
+	// rank[i] 是 update[i] 的排名
+	rank := make([]int, MaxLevel)
+	curr := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		if i < sl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for curr.Forward[i] != nil && sl.compare(curr.Forward[i].Val, Val) < 0 {
+			rank[i] += curr.Span[i]
+			curr = curr.Forward[i]
+		}
+		update[i] = curr
+	}
 	level := sl.randomLevel()
 	if level > sl.level {
 		for i := sl.level; i < level; i++ {
 			update[i] = sl.header
+			sl.header.Span[i] = sl.size
 		}
 		sl.level = level
 	}
@@ -122,6 +138,13 @@ func (sl *SkipList[T]) Insert(Val T) {
 	for i := 0; i < level; i++ {
 		newNode.Forward[i] = update[i].Forward[i]
 		update[i].Forward[i] = newNode
+		// rank[0] - rank[i] 是 update[i] 到新结点的前驱之间的距离
+		newNode.Span[i] = update[i].Span[i] - (rank[0] - rank[i])
+		update[i].Span[i] = rank[0] - rank[i] + 1
+	}
+	// 更高的层只是多跨过了一个结点
+	for i := level; i < sl.level; i++ {
+		update[i].Span[i]++
 	}
 
 	sl.size += 1
@@ -139,8 +162,13 @@ func (sl *SkipList[T]) DeleteElement(target T) bool {
 		return true
 	}
 	// 删除target结点
-	for i := 0; i < sl.level && update[i].Forward[i] == node; i++ {
-		update[i].Forward[i] = node.Forward[i]
+	for i := 0; i < sl.level; i++ {
+		if update[i].Forward[i] == node {
+			update[i].Span[i] += node.Span[i] - 1
+			update[i].Forward[i] = node.Forward[i]
+		} else {
+			update[i].Span[i]--
+		}
 	}
 
 	// 更新层级
@@ -161,14 +189,107 @@ func (sl *SkipList[T]) Peek() (T, error) {
 	return curr.Val, nil
 }
 
+// Get 返回排名为 index 的元素，排名从 0 开始，时间复杂度是 O(logN)
 func (sl *SkipList[T]) Get(index int) (T, error) {
 	var zero T
 	if index < 0 || index >= sl.size {
 		return zero, errs.NewErrIndexOutOfRange(sl.size, index)
 	}
+	return sl.nodeByRank(index + 1).Val, nil
+}
+
+// Rank 返回第一个和 target 相等的元素的排名，排名从 0 开始
+// target 不存在的时候返回 -1
+func (sl *SkipList[T]) Rank(target T) int {
+	rank := 0
 	curr := sl.header
-	for i := 0; i <= index; i++ {
-		curr = curr.Forward[0]
+	for i := sl.level - 1; i >= 0; i-- {
+		for curr.Forward[i] != nil && sl.compare(curr.Forward[i].Val, target) < 0 {
+			rank += curr.Span[i]
+			curr = curr.Forward[i]
+		}
 	}
-	return curr.Val, nil
+	curr = curr.Forward[0]
+	if curr == nil || sl.compare(curr.Val, target) != 0 {
+		return -1
+	}
+	return rank
+}
+
+// RangeByRank 返回排名在 [start, stop] 之间的元素，排名从 0 开始
+// 和 Redis 的 ZRANGE 一样，负数表示从末尾开始计算，例如 -1 是最后一个元素
+// 超出范围的部分会被忽略
+func (sl *SkipList[T]) RangeByRank(start, stop int) []T {
+	if start < 0 {
+		start += sl.size
+	}
+	if stop < 0 {
+		stop += sl.size
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= sl.size {
+		stop = sl.size - 1
+	}
+	if start > stop {
+		return []T{}
+	}
+	res := make([]T, 0, stop-start+1)
+	for curr := sl.nodeByRank(start + 1); len(res) < cap(res); curr = curr.Forward[0] {
+		res = append(res, curr.Val)
+	}
+	return res
+}
+
+// RangeByScore 返回在 [min, max] 之间的元素
+func (sl *SkipList[T]) RangeByScore(min, max T) []T {
+	res := make([]T, 0)
+	curr, _ := sl.traverse(min, sl.level)
+	for curr = curr.Forward[0]; curr != nil && sl.compare(curr.Val, max) <= 0; curr = curr.Forward[0] {
+		res = append(res, curr.Val)
+	}
+	return res
+}
+
+// Floor 返回小于等于 target 的最大的元素
+func (sl *SkipList[T]) Floor(target T) (T, bool) {
+	curr := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for curr.Forward[i] != nil && sl.compare(curr.Forward[i].Val, target) <= 0 {
+			curr = curr.Forward[i]
+		}
+	}
+	if curr == sl.header {
+		var zero T
+		return zero, false
+	}
+	return curr.Val, true
+}
+
+// Ceiling 返回大于等于 target 的最小的元素
+func (sl *SkipList[T]) Ceiling(target T) (T, bool) {
+	curr, _ := sl.traverse(target, sl.level)
+	curr = curr.Forward[0]
+	if curr == nil {
+		var zero T
+		return zero, false
+	}
+	return curr.Val, true
+}
+
+// nodeByRank 返回排名为 rank 的结点，这里的排名从 1 开始，调用者需要确保 rank 在范围之内
+func (sl *SkipList[T]) nodeByRank(rank int) *skipListNode[T] {
+	traversed := 0
+	curr := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for curr.Forward[i] != nil && traversed+curr.Span[i] <= rank {
+			traversed += curr.Span[i]
+			curr = curr.Forward[i]
+		}
+		if traversed == rank {
+			return curr
+		}
+	}
+	return curr
 }
@@ -17,6 +17,7 @@ package list
 import (
 	"errors"
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/ecodeclub/ekit"
@@ -457,3 +458,231 @@ func TestSkipList_AsSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestSkipList_Rank(t *testing.T) {
+	testCases := []struct {
+		name     string
+		skiplist *SkipList[int]
+		target   int
+		wantRank int
+	}{
+		{
+			name:     "empty",
+			skiplist: NewSkipList[int](ekit.ComparatorRealNumber[int]),
+			target:   1,
+			wantRank: -1,
+		},
+		{
+			name:     "first",
+			skiplist: NewSkipListFromSlice[int]([]int{3, 1, 2}, ekit.ComparatorRealNumber[int]),
+			target:   1,
+			wantRank: 0,
+		},
+		{
+			name:     "last",
+			skiplist: NewSkipListFromSlice[int]([]int{3, 1, 2}, ekit.ComparatorRealNumber[int]),
+			target:   3,
+			wantRank: 2,
+		},
+		{
+			name:     "duplicate",
+			skiplist: NewSkipListFromSlice[int]([]int{1, 2, 2, 2, 3}, ekit.ComparatorRealNumber[int]),
+			target:   2,
+			wantRank: 1,
+		},
+		{
+			name:     "not found",
+			skiplist: NewSkipListFromSlice[int]([]int{1, 3}, ekit.ComparatorRealNumber[int]),
+			target:   2,
+			wantRank: -1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantRank, tc.skiplist.Rank(tc.target))
+		})
+	}
+}
+
+func TestSkipList_RangeByRank(t *testing.T) {
+	testCases := []struct {
+		name      string
+		skiplist  *SkipList[int]
+		start     int
+		stop      int
+		wantSlice []int
+	}{
+		{
+			name:      "empty",
+			skiplist:  NewSkipList[int](ekit.ComparatorRealNumber[int]),
+			start:     0,
+			stop:      -1,
+			wantSlice: []int{},
+		},
+		{
+			name:      "all",
+			skiplist:  NewSkipListFromSlice[int]([]int{5, 4, 3, 2, 1}, ekit.ComparatorRealNumber[int]),
+			start:     0,
+			stop:      -1,
+			wantSlice: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:      "middle",
+			skiplist:  NewSkipListFromSlice[int]([]int{5, 4, 3, 2, 1}, ekit.ComparatorRealNumber[int]),
+			start:     1,
+			stop:      3,
+			wantSlice: []int{2, 3, 4},
+		},
+		{
+			name:      "negative",
+			skiplist:  NewSkipListFromSlice[int]([]int{5, 4, 3, 2, 1}, ekit.ComparatorRealNumber[int]),
+			start:     -2,
+			stop:      -1,
+			wantSlice: []int{4, 5},
+		},
+		{
+			name:      "out of range",
+			skiplist:  NewSkipListFromSlice[int]([]int{5, 4, 3, 2, 1}, ekit.ComparatorRealNumber[int]),
+			start:     -10,
+			stop:      10,
+			wantSlice: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:      "start greater than stop",
+			skiplist:  NewSkipListFromSlice[int]([]int{5, 4, 3, 2, 1}, ekit.ComparatorRealNumber[int]),
+			start:     3,
+			stop:      1,
+			wantSlice: []int{},
+		},
+		{
+			name:      "start out of range",
+			skiplist:  NewSkipListFromSlice[int]([]int{5, 4, 3, 2, 1}, ekit.ComparatorRealNumber[int]),
+			start:     5,
+			stop:      10,
+			wantSlice: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantSlice, tc.skiplist.RangeByRank(tc.start, tc.stop))
+		})
+	}
+}
+
+func TestSkipList_RangeByScore(t *testing.T) {
+	testCases := []struct {
+		name      string
+		skiplist  *SkipList[int]
+		min       int
+		max       int
+		wantSlice []int
+	}{
+		{
+			name:      "empty",
+			skiplist:  NewSkipList[int](ekit.ComparatorRealNumber[int]),
+			min:       0,
+			max:       10,
+			wantSlice: []int{},
+		},
+		{
+			name:      "inclusive",
+			skiplist:  NewSkipListFromSlice[int]([]int{1, 3, 3, 5, 7}, ekit.ComparatorRealNumber[int]),
+			min:       3,
+			max:       5,
+			wantSlice: []int{3, 3, 5},
+		},
+		{
+			name:      "between",
+			skiplist:  NewSkipListFromSlice[int]([]int{1, 3, 5, 7}, ekit.ComparatorRealNumber[int]),
+			min:       2,
+			max:       6,
+			wantSlice: []int{3, 5},
+		},
+		{
+			name:      "no element",
+			skiplist:  NewSkipListFromSlice[int]([]int{1, 3, 5, 7}, ekit.ComparatorRealNumber[int]),
+			min:       8,
+			max:       10,
+			wantSlice: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantSlice, tc.skiplist.RangeByScore(tc.min, tc.max))
+		})
+	}
+}
+
+func TestSkipList_FloorCeiling(t *testing.T) {
+	sl := NewSkipListFromSlice[int]([]int{1, 3, 5}, ekit.ComparatorRealNumber[int])
+	testCases := []struct {
+		name        string
+		target      int
+		wantFloor   int
+		wantFloorOk bool
+		wantCeil    int
+		wantCeilOk  bool
+	}{
+		{
+			name:       "less than all",
+			target:     0,
+			wantCeil:   1,
+			wantCeilOk: true,
+		},
+		{
+			name:        "equal",
+			target:      3,
+			wantFloor:   3,
+			wantFloorOk: true,
+			wantCeil:    3,
+			wantCeilOk:  true,
+		},
+		{
+			name:        "between",
+			target:      4,
+			wantFloor:   3,
+			wantFloorOk: true,
+			wantCeil:    5,
+			wantCeilOk:  true,
+		},
+		{
+			name:        "greater than all",
+			target:      6,
+			wantFloor:   5,
+			wantFloorOk: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			val, ok := sl.Floor(tc.target)
+			assert.Equal(t, tc.wantFloorOk, ok)
+			assert.Equal(t, tc.wantFloor, val)
+			val, ok = sl.Ceiling(tc.target)
+			assert.Equal(t, tc.wantCeilOk, ok)
+			assert.Equal(t, tc.wantCeil, val)
+		})
+	}
+}
+
+// TestSkipList_Span 随机插入和删除之后，通过 span 计算出来的排名依旧是正确的
+func TestSkipList_Span(t *testing.T) {
+	sl := NewSkipList[int](ekit.ComparatorRealNumber[int])
+	for i := 0; i < 1000; i++ {
+		sl.Insert(rand.Intn(200))
+		if i%3 == 0 {
+			sl.DeleteElement(rand.Intn(200))
+		}
+	}
+	vals := sl.AsSlice()
+	assert.Equal(t, len(vals), sl.Len())
+	for i, val := range vals {
+		got, err := sl.Get(i)
+		assert.NoError(t, err)
+		assert.Equal(t, val, got)
+		rank := sl.Rank(val)
+		assert.Equal(t, val, vals[rank])
+		if rank > 0 {
+			assert.Less(t, vals[rank-1], val)
+		}
+	}
+}
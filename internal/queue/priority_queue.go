@@ -99,6 +99,64 @@ func (p *PriorityQueue[T]) Dequeue() (T, error) {
 	return pop, nil
 }
 
+// Remove 删除所有满足 filter 的元素，返回被删除的元素
+// 删除之后会重新建堆，时间复杂度是 O(n)
+func (p *PriorityQueue[T]) Remove(filter func(t T) bool) []T {
+	var removed []T
+	n := 0
+	for _, t := range p.data {
+		if filter(t) {
+			removed = append(removed, t)
+			continue
+		}
+		p.data[n] = t
+		n++
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	// 为了释放内存，GC
+	var zero T
+	for i := n; i < len(p.data); i++ {
+		p.data[i] = zero
+	}
+	p.data = p.data[:n]
+	p.shrinkIfNecessary()
+	for i := n/2 - 1; i >= 0; i-- {
+		p.heapify(p.data, n, i)
+	}
+	return removed
+}
+
+// Update 用 t 替换第一个满足 filter 的元素，并且调整该元素在堆中的位置
+// 如果没有满足条件的元素，返回 false
+func (p *PriorityQueue[T]) Update(filter func(t T) bool, t T) bool {
+	for i, v := range p.data {
+		if filter(v) {
+			p.data[i] = t
+			p.fix(i)
+			return true
+		}
+	}
+	return false
+}
+
+// fix 在下标 i 的元素发生变化之后，恢复堆的性质
+func (p *PriorityQueue[T]) fix(i int) {
+	node := i
+	for node > 0 {
+		parent := (node - 1) / 2
+		if p.compare(p.data[node], p.data[parent]) >= 0 {
+			break
+		}
+		p.data[parent], p.data[node] = p.data[node], p.data[parent]
+		node = parent
+	}
+	if node == i {
+		p.heapify(p.data, len(p.data), i)
+	}
+}
+
 func (p *PriorityQueue[T]) shrinkIfNecessary() {
 	if p.IsBoundless() {
 		p.data = slice.Shrink[T](p.data)
@@ -472,6 +472,121 @@ func TestPriorityQueue_Shrink(t *testing.T) {
 	}
 }
 
+func TestPriorityQueue_Remove(t *testing.T) {
+	testCases := []struct {
+		name        string
+		q           *PriorityQueue[int]
+		filter      func(t int) bool
+		wantRemoved []int
+		wantData    []int
+	}{
+		{
+			name:     "empty",
+			q:        NewPriorityQueue[int](0, compare()),
+			filter:   func(t int) bool { return true },
+			wantData: []int{},
+		},
+		{
+			name:     "no match",
+			q:        priorityQueueOf(0, []int{6, 5, 4, 3, 2, 1}, compare()),
+			filter:   func(t int) bool { return t > 10 },
+			wantData: []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:        "remove head",
+			q:           priorityQueueOf(0, []int{6, 5, 4, 3, 2, 1}, compare()),
+			filter:      func(t int) bool { return t == 1 },
+			wantRemoved: []int{1},
+			wantData:    []int{2, 3, 4, 5, 6},
+		},
+		{
+			name:        "remove even",
+			q:           priorityQueueOf(6, []int{6, 5, 4, 3, 2, 1}, compare()),
+			filter:      func(t int) bool { return t%2 == 0 },
+			wantRemoved: []int{2, 4, 6},
+			wantData:    []int{1, 3, 5},
+		},
+		{
+			name:        "remove all",
+			q:           priorityQueueOf(0, []int{6, 5, 4, 3, 2, 1}, compare()),
+			filter:      func(t int) bool { return true },
+			wantRemoved: []int{1, 2, 3, 4, 5, 6},
+			wantData:    []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			removed := tc.q.Remove(tc.filter)
+			assert.ElementsMatch(t, tc.wantRemoved, removed)
+			res := make([]int, 0, tc.q.Len())
+			for tc.q.Len() > 0 {
+				el, err := tc.q.Dequeue()
+				require.NoError(t, err)
+				res = append(res, el)
+			}
+			assert.Equal(t, tc.wantData, res)
+		})
+	}
+}
+
+func TestPriorityQueue_Update(t *testing.T) {
+	testCases := []struct {
+		name     string
+		q        *PriorityQueue[int]
+		filter   func(t int) bool
+		val      int
+		wantOk   bool
+		wantData []int
+	}{
+		{
+			name:     "no match",
+			q:        priorityQueueOf(0, []int{6, 5, 4, 3, 2, 1}, compare()),
+			filter:   func(t int) bool { return t > 10 },
+			val:      0,
+			wantData: []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			// 变小之后上浮
+			name:     "sift up",
+			q:        priorityQueueOf(0, []int{6, 5, 4, 3, 2, 1}, compare()),
+			filter:   func(t int) bool { return t == 5 },
+			val:      0,
+			wantOk:   true,
+			wantData: []int{0, 1, 2, 3, 4, 6},
+		},
+		{
+			// 变大之后下沉
+			name:     "sift down",
+			q:        priorityQueueOf(0, []int{6, 5, 4, 3, 2, 1}, compare()),
+			filter:   func(t int) bool { return t == 1 },
+			val:      10,
+			wantOk:   true,
+			wantData: []int{2, 3, 4, 5, 6, 10},
+		},
+		{
+			name:     "same position",
+			q:        priorityQueueOf(0, []int{6, 5, 4, 3, 2, 1}, compare()),
+			filter:   func(t int) bool { return t == 3 },
+			val:      3,
+			wantOk:   true,
+			wantData: []int{1, 2, 3, 4, 5, 6},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok := tc.q.Update(tc.filter, tc.val)
+			assert.Equal(t, tc.wantOk, ok)
+			res := make([]int, 0, tc.q.Len())
+			for tc.q.Len() > 0 {
+				el, err := tc.q.Dequeue()
+				require.NoError(t, err)
+				res = append(res, el)
+			}
+			assert.Equal(t, tc.wantData, res)
+		})
+	}
+}
+
 func priorityQueueOf(capacity int, data []int, compare ekit.Comparator[int]) *PriorityQueue[int] {
 	q := NewPriorityQueue[int](capacity, compare)
 	for _, el := range data {
@@ -0,0 +1,225 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tree
+
+// Bound 是区间的一个端点
+type Bound[K any] struct {
+	Key K
+	// Inclusive 区间是否包含 Key
+	Inclusive bool
+	// Unbounded 为 true 的时候表示这一端没有限制，此时 Key 和 Inclusive 会被忽略
+	Unbounded bool
+}
+
+// First 返回最小的键值对，树为空的时候返回 false
+func (rb *RBTree[K, V]) First() (K, V, bool) {
+	return rb.result(rb.minNode())
+}
+
+// Last 返回最大的键值对，树为空的时候返回 false
+func (rb *RBTree[K, V]) Last() (K, V, bool) {
+	return rb.result(rb.maxNode())
+}
+
+// Floor 返回小于等于 key 的最大的键值对
+func (rb *RBTree[K, V]) Floor(key K) (K, V, bool) {
+	return rb.result(rb.floorNode(key))
+}
+
+// Ceiling 返回大于等于 key 的最小的键值对
+func (rb *RBTree[K, V]) Ceiling(key K) (K, V, bool) {
+	return rb.result(rb.ceilingNode(key))
+}
+
+// Higher 返回严格大于 key 的最小的键值对
+func (rb *RBTree[K, V]) Higher(key K) (K, V, bool) {
+	return rb.result(rb.higherNode(key))
+}
+
+// Lower 返回严格小于 key 的最大的键值对
+func (rb *RBTree[K, V]) Lower(key K) (K, V, bool) {
+	return rb.result(rb.lowerNode(key))
+}
+
+// AscendRange 按照 key 从小到大的顺序遍历 [lo, hi] 范围内的键值对，端点是否包含取决于 Bound
+// cb 返回 false 的时候结束遍历。遍历的过程中不能修改树
+func (rb *RBTree[K, V]) AscendRange(lo, hi Bound[K], cb func(key K, value V) bool) {
+	var node *rbNode[K, V]
+	switch {
+	case lo.Unbounded:
+		node = rb.minNode()
+	case lo.Inclusive:
+		node = rb.ceilingNode(lo.Key)
+	default:
+		node = rb.higherNode(lo.Key)
+	}
+	for ; node != nil && rb.beforeHi(node.key, hi); node = rb.findSuccessor(node) {
+		if !cb(node.key, node.value) {
+			return
+		}
+	}
+}
+
+// DescendRange 按照 key 从大到小的顺序遍历 [lo, hi] 范围内的键值对，端点是否包含取决于 Bound
+// cb 返回 false 的时候结束遍历。遍历的过程中不能修改树
+func (rb *RBTree[K, V]) DescendRange(lo, hi Bound[K], cb func(key K, value V) bool) {
+	var node *rbNode[K, V]
+	switch {
+	case hi.Unbounded:
+		node = rb.maxNode()
+	case hi.Inclusive:
+		node = rb.floorNode(hi.Key)
+	default:
+		node = rb.lowerNode(hi.Key)
+	}
+	for ; node != nil && rb.afterLo(node.key, lo); node = rb.findPredecessor(node) {
+		if !cb(node.key, node.value) {
+			return
+		}
+	}
+}
+
+// InRange 判断 key 是否在 [lo, hi] 范围内，端点是否包含取决于 Bound
+func (rb *RBTree[K, V]) InRange(key K, lo, hi Bound[K]) bool {
+	return rb.afterLo(key, lo) && rb.beforeHi(key, hi)
+}
+
+func (rb *RBTree[K, V]) afterLo(key K, lo Bound[K]) bool {
+	if lo.Unbounded {
+		return true
+	}
+	cmp := rb.compare(key, lo.Key)
+	return cmp > 0 || (cmp == 0 && lo.Inclusive)
+}
+
+func (rb *RBTree[K, V]) beforeHi(key K, hi Bound[K]) bool {
+	if hi.Unbounded {
+		return true
+	}
+	cmp := rb.compare(key, hi.Key)
+	return cmp < 0 || (cmp == 0 && hi.Inclusive)
+}
+
+func (rb *RBTree[K, V]) result(node *rbNode[K, V]) (K, V, bool) {
+	if node == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return node.key, node.value, true
+}
+
+func (rb *RBTree[K, V]) minNode() *rbNode[K, V] {
+	node := rb.root
+	for node != nil && node.left != nil {
+		node = node.left
+	}
+	return node
+}
+
+func (rb *RBTree[K, V]) maxNode() *rbNode[K, V] {
+	node := rb.root
+	for node != nil && node.right != nil {
+		node = node.right
+	}
+	return node
+}
+
+func (rb *RBTree[K, V]) floorNode(key K) *rbNode[K, V] {
+	var res *rbNode[K, V]
+	node := rb.root
+	for node != nil {
+		cmp := rb.compare(key, node.key)
+		if cmp == 0 {
+			return node
+		}
+		if cmp > 0 {
+			res = node
+			node = node.right
+		} else {
+			node = node.left
+		}
+	}
+	return res
+}
+
+func (rb *RBTree[K, V]) ceilingNode(key K) *rbNode[K, V] {
+	var res *rbNode[K, V]
+	node := rb.root
+	for node != nil {
+		cmp := rb.compare(key, node.key)
+		if cmp == 0 {
+			return node
+		}
+		if cmp < 0 {
+			res = node
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return res
+}
+
+func (rb *RBTree[K, V]) higherNode(key K) *rbNode[K, V] {
+	var res *rbNode[K, V]
+	node := rb.root
+	for node != nil {
+		if rb.compare(key, node.key) < 0 {
+			res = node
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return res
+}
+
+func (rb *RBTree[K, V]) lowerNode(key K) *rbNode[K, V] {
+	var res *rbNode[K, V]
+	node := rb.root
+	for node != nil {
+		if rb.compare(key, node.key) > 0 {
+			res = node
+			node = node.right
+		} else {
+			node = node.left
+		}
+	}
+	return res
+}
+
+// findPredecessor 寻找前驱节点，和 findSuccessor 是对称的
+// case1: node节点存在左子节点,则左子树的最大节点是node的前驱节点
+// case2: node节点不存在左子节点,则其第一个为右节点的祖先的父节点为node的前驱节点
+func (rb *RBTree[K, V]) findPredecessor(node *rbNode[K, V]) *rbNode[K, V] {
+	if node == nil {
+		return nil
+	}
+	if node.left != nil {
+		p := node.left
+		for p.right != nil {
+			p = p.right
+		}
+		return p
+	}
+	p := node.parent
+	ch := node
+	for p != nil && ch == p.left {
+		ch = p
+		p = p.parent
+	}
+	return p
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRBTree_FirstLast(t *testing.T) {
+	rb := NewRBTree[int, int](compare())
+	_, _, ok := rb.First()
+	assert.False(t, ok)
+	_, _, ok = rb.Last()
+	assert.False(t, ok)
+
+	rb = newNavigateTree(t, 5, 3, 8, 1, 9)
+	k, v, ok := rb.First()
+	assert.True(t, ok)
+	assert.Equal(t, 1, k)
+	assert.Equal(t, 10, v)
+	k, v, ok = rb.Last()
+	assert.True(t, ok)
+	assert.Equal(t, 9, k)
+	assert.Equal(t, 90, v)
+}
+
+func TestRBTree_Navigate(t *testing.T) {
+	rb := newNavigateTree(t, 10, 20, 30, 40, 50)
+	testCases := []struct {
+		name string
+		key  int
+		// -1 代表不存在
+		wantFloor   int
+		wantCeiling int
+		wantHigher  int
+		wantLower   int
+	}{
+		{
+			name:        "less than all",
+			key:         5,
+			wantFloor:   -1,
+			wantCeiling: 10,
+			wantHigher:  10,
+			wantLower:   -1,
+		},
+		{
+			name:        "equal to first",
+			key:         10,
+			wantFloor:   10,
+			wantCeiling: 10,
+			wantHigher:  20,
+			wantLower:   -1,
+		},
+		{
+			name:        "between",
+			key:         25,
+			wantFloor:   20,
+			wantCeiling: 30,
+			wantHigher:  30,
+			wantLower:   20,
+		},
+		{
+			name:        "equal to middle",
+			key:         30,
+			wantFloor:   30,
+			wantCeiling: 30,
+			wantHigher:  40,
+			wantLower:   20,
+		},
+		{
+			name:        "equal to last",
+			key:         50,
+			wantFloor:   50,
+			wantCeiling: 50,
+			wantHigher:  -1,
+			wantLower:   40,
+		},
+		{
+			name:        "greater than all",
+			key:         55,
+			wantFloor:   50,
+			wantCeiling: -1,
+			wantHigher:  -1,
+			wantLower:   50,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertNavigate(t, tc.wantFloor, rb.Floor, tc.key)
+			assertNavigate(t, tc.wantCeiling, rb.Ceiling, tc.key)
+			assertNavigate(t, tc.wantHigher, rb.Higher, tc.key)
+			assertNavigate(t, tc.wantLower, rb.Lower, tc.key)
+		})
+	}
+}
+
+func TestRBTree_Range(t *testing.T) {
+	rb := newNavigateTree(t, 10, 20, 30, 40, 50)
+	unbounded := Bound[int]{Unbounded: true}
+	testCases := []struct {
+		name     string
+		lo       Bound[int]
+		hi       Bound[int]
+		wantKeys []int
+	}{
+		{
+			name:     "all",
+			lo:       unbounded,
+			hi:       unbounded,
+			wantKeys: []int{10, 20, 30, 40, 50},
+		},
+		{
+			name:     "inclusive",
+			lo:       Bound[int]{Key: 20, Inclusive: true},
+			hi:       Bound[int]{Key: 40, Inclusive: true},
+			wantKeys: []int{20, 30, 40},
+		},
+		{
+			name:     "exclusive",
+			lo:       Bound[int]{Key: 20},
+			hi:       Bound[int]{Key: 40},
+			wantKeys: []int{30},
+		},
+		{
+			name:     "key not exist",
+			lo:       Bound[int]{Key: 15},
+			hi:       Bound[int]{Key: 45, Inclusive: true},
+			wantKeys: []int{20, 30, 40},
+		},
+		{
+			name:     "head",
+			lo:       unbounded,
+			hi:       Bound[int]{Key: 30},
+			wantKeys: []int{10, 20},
+		},
+		{
+			name:     "tail",
+			lo:       Bound[int]{Key: 30, Inclusive: true},
+			hi:       unbounded,
+			wantKeys: []int{30, 40, 50},
+		},
+		{
+			name:     "empty",
+			lo:       Bound[int]{Key: 30},
+			hi:       Bound[int]{Key: 30, Inclusive: true},
+			wantKeys: []int{},
+		},
+		{
+			name:     "lo greater than hi",
+			lo:       Bound[int]{Key: 40, Inclusive: true},
+			hi:       Bound[int]{Key: 20, Inclusive: true},
+			wantKeys: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asc := make([]int, 0, len(tc.wantKeys))
+			rb.AscendRange(tc.lo, tc.hi, func(key int, value int) bool {
+				asc = append(asc, key)
+				return true
+			})
+			assert.Equal(t, tc.wantKeys, asc)
+
+			desc := make([]int, 0, len(tc.wantKeys))
+			rb.DescendRange(tc.lo, tc.hi, func(key int, value int) bool {
+				desc = append(desc, key)
+				return true
+			})
+			for i, j := 0, len(desc)-1; i < j; i, j = i+1, j-1 {
+				desc[i], desc[j] = desc[j], desc[i]
+			}
+			assert.Equal(t, tc.wantKeys, desc)
+		})
+	}
+
+	t.Run("stop early", func(t *testing.T) {
+		keys := make([]int, 0, 2)
+		rb.DescendRange(unbounded, unbounded, func(key int, value int) bool {
+			keys = append(keys, key)
+			return len(keys) < 2
+		})
+		assert.Equal(t, []int{50, 40}, keys)
+	})
+}
+
+func newNavigateTree(t *testing.T, keys ...int) *RBTree[int, int] {
+	rb := NewRBTree[int, int](compare())
+	for _, key := range keys {
+		require.NoError(t, rb.Add(key, key*10))
+	}
+	return rb
+}
+
+func assertNavigate(t *testing.T, want int, fn func(key int) (int, int, bool), key int) {
+	k, v, ok := fn(key)
+	if want < 0 {
+		assert.False(t, ok)
+		return
+	}
+	assert.True(t, ok)
+	assert.Equal(t, want, k)
+	assert.Equal(t, want*10, v)
+}
@@ -113,3 +113,57 @@ func (a *ArrayList[T]) AsSlice() []T {
 	copy(res, a.vals)
 	return res
 }
+
+// Iterator 返回一个从头到尾的迭代器
+// 通过迭代器删除元素可能会引起缩容
+func (a *ArrayList[T]) Iterator() Iterator[T] {
+	return &arrayListIterator[T]{list: a, cur: -1}
+}
+
+type arrayListIterator[T any] struct {
+	list *ArrayList[T]
+	// cur 当前元素的下标，-1 表示没有指向任何元素
+	cur int
+	// next 下一个元素的下标
+	next int
+}
+
+func (it *arrayListIterator[T]) Next() bool {
+	if it.next >= it.list.Len() {
+		it.cur = -1
+		return false
+	}
+	it.cur = it.next
+	it.next++
+	return true
+}
+
+func (it *arrayListIterator[T]) Value() T {
+	if it.cur < 0 {
+		var t T
+		return t
+	}
+	return it.list.vals[it.cur]
+}
+
+func (it *arrayListIterator[T]) Remove() error {
+	if it.cur < 0 {
+		return ErrIteratorNoElement
+	}
+	_, err := it.list.Delete(it.cur)
+	if err != nil {
+		return err
+	}
+	// 后面的元素都往前移动了一位
+	it.next = it.cur
+	it.cur = -1
+	return nil
+}
+
+func (it *arrayListIterator[T]) Set(t T) error {
+	if it.cur < 0 {
+		return ErrIteratorNoElement
+	}
+	it.list.vals[it.cur] = t
+	return nil
+}
@@ -563,3 +563,66 @@ func TestArrayList_Set(t *testing.T) {
 		})
 	}
 }
+
+func TestArrayList_Iterator(t *testing.T) {
+	testCases := []struct {
+		name      string
+		list      *ArrayList[int]
+		wantVals  []int
+		wantSlice []int
+	}{
+		{
+			name:      "empty",
+			list:      NewArrayList[int](0),
+			wantVals:  []int{},
+			wantSlice: []int{},
+		},
+		{
+			name:      "remove even and set odd",
+			list:      NewArrayListOf[int]([]int{1, 2, 3, 4, 4, 5}),
+			wantVals:  []int{1, 2, 3, 4, 4, 5},
+			wantSlice: []int{10, 30, 50},
+		},
+		{
+			name:      "remove all",
+			list:      NewArrayListOf[int]([]int{2, 4, 6}),
+			wantVals:  []int{2, 4, 6},
+			wantSlice: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vals := make([]int, 0, tc.list.Len())
+			for it := tc.list.Iterator(); it.Next(); {
+				v := it.Value()
+				vals = append(vals, v)
+				if v%2 == 0 {
+					assert.NoError(t, it.Remove())
+					continue
+				}
+				assert.NoError(t, it.Set(v*10))
+			}
+			assert.Equal(t, tc.wantVals, vals)
+			assert.Equal(t, tc.wantSlice, tc.list.AsSlice())
+		})
+	}
+}
+
+func TestArrayList_Iterator_NoElement(t *testing.T) {
+	list := NewArrayListOf[int]([]int{1})
+	it := list.Iterator()
+	// 还没有调用 Next
+	assert.Equal(t, 0, it.Value())
+	assert.Equal(t, ErrIteratorNoElement, it.Remove())
+	assert.Equal(t, ErrIteratorNoElement, it.Set(2))
+
+	assert.True(t, it.Next())
+	assert.NoError(t, it.Remove())
+	// 已经删除了
+	assert.Equal(t, 0, it.Value())
+	assert.Equal(t, ErrIteratorNoElement, it.Remove())
+	assert.Equal(t, ErrIteratorNoElement, it.Set(2))
+
+	assert.False(t, it.Next())
+	assert.Equal(t, ErrIteratorNoElement, it.Remove())
+}
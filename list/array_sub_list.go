@@ -0,0 +1,118 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import "github.com/ecodeclub/ekit/internal/errs"
+
+var (
+	_ List[any] = &arraySubList[any]{}
+)
+
+// SubList 返回 [from, to) 范围内的视图，视图和 ArrayList 共享底层存储：
+// 通过视图进行的修改，包括增加和删除元素，都会反映到 ArrayList 上，反之亦然。
+// 但是在创建视图之后，如果绕过视图对 ArrayList 增加或者删除元素，那么视图的行为是未定义的
+func (a *ArrayList[T]) SubList(from, to int) (List[T], error) {
+	if from < 0 || from > to || to > len(a.vals) {
+		return nil, errs.NewErrInvalidRange(len(a.vals), from, to)
+	}
+	return &arraySubList[T]{parent: a, offset: from, size: to - from}, nil
+}
+
+// arraySubList 是 ArrayList 的一段视图
+type arraySubList[T any] struct {
+	parent *ArrayList[T]
+	// offset 视图的起点在 parent 中的下标
+	offset int
+	size   int
+}
+
+func (s *arraySubList[T]) Get(index int) (T, error) {
+	if index < 0 || index >= s.size {
+		var t T
+		return t, errs.NewErrIndexOutOfRange(s.size, index)
+	}
+	return s.parent.vals[s.offset+index], nil
+}
+
+// Append 在视图的末尾追加元素，也就是插入到 ArrayList 中视图之后的位置
+func (s *arraySubList[T]) Append(ts ...T) error {
+	pos := s.offset + s.size
+	n := len(s.parent.vals)
+	vals := append(s.parent.vals, ts...)
+	// 把视图之后的元素往后挪，腾出位置
+	copy(vals[pos+len(ts):], vals[pos:n])
+	copy(vals[pos:], ts)
+	s.parent.vals = vals
+	s.size += len(ts)
+	return nil
+}
+
+func (s *arraySubList[T]) Add(index int, t T) error {
+	if index < 0 || index > s.size {
+		return errs.NewErrIndexOutOfRange(s.size, index)
+	}
+	err := s.parent.Add(s.offset+index, t)
+	if err != nil {
+		return err
+	}
+	s.size++
+	return nil
+}
+
+func (s *arraySubList[T]) Set(index int, t T) error {
+	if index < 0 || index >= s.size {
+		return errs.NewErrIndexOutOfRange(s.size, index)
+	}
+	s.parent.vals[s.offset+index] = t
+	return nil
+}
+
+func (s *arraySubList[T]) Delete(index int) (T, error) {
+	if index < 0 || index >= s.size {
+		var t T
+		return t, errs.NewErrIndexOutOfRange(s.size, index)
+	}
+	t, err := s.parent.Delete(s.offset + index)
+	if err != nil {
+		return t, err
+	}
+	s.size--
+	return t, nil
+}
+
+func (s *arraySubList[T]) Len() int {
+	return s.size
+}
+
+// Cap 视图没有自己的容量，所以返回的是长度
+func (s *arraySubList[T]) Cap() int {
+	return s.size
+}
+
+func (s *arraySubList[T]) Range(fn func(index int, t T) error) error {
+	for i, val := range s.parent.vals[s.offset : s.offset+s.size] {
+		err := fn(i, val)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *arraySubList[T]) AsSlice() []T {
+	res := make([]T, s.size)
+	copy(res, s.parent.vals[s.offset:s.offset+s.size])
+	return res
+}
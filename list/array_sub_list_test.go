@@ -0,0 +1,127 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrayList_SubList(t *testing.T) {
+	testCases := []struct {
+		name      string
+		from      int
+		to        int
+		wantSlice []int
+		wantErr   string
+	}{
+		{
+			name:      "all",
+			from:      0,
+			to:        5,
+			wantSlice: []int{0, 1, 2, 3, 4},
+		},
+		{
+			name:      "middle",
+			from:      1,
+			to:        3,
+			wantSlice: []int{1, 2},
+		},
+		{
+			name:      "empty",
+			from:      5,
+			to:        5,
+			wantSlice: []int{},
+		},
+		{
+			name:    "negative from",
+			from:    -1,
+			to:      3,
+			wantErr: "ekit: 无效的范围 [-1, 3)，长度 5",
+		},
+		{
+			name:    "from greater than to",
+			from:    3,
+			to:      2,
+			wantErr: "ekit: 无效的范围 [3, 2)，长度 5",
+		},
+		{
+			name:    "to out of range",
+			from:    0,
+			to:      6,
+			wantErr: "ekit: 无效的范围 [0, 6)，长度 5",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewArrayListOf[int]([]int{0, 1, 2, 3, 4})
+			sub, err := l.SubList(tc.from, tc.to)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantSlice, sub.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), sub.Len())
+			assert.Equal(t, len(tc.wantSlice), sub.Cap())
+		})
+	}
+}
+
+func TestArraySubList_Modify(t *testing.T) {
+	l := NewArrayListOf[int]([]int{0, 1, 2, 3, 4})
+	sub, err := l.SubList(1, 3)
+	require.NoError(t, err)
+
+	// 共享底层存储
+	require.NoError(t, sub.Set(0, 10))
+	assert.Equal(t, []int{0, 10, 2, 3, 4}, l.AsSlice())
+	require.NoError(t, l.Set(2, 20))
+	val, err := sub.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, 20, val)
+
+	require.NoError(t, sub.Append(5, 6))
+	assert.Equal(t, []int{10, 20, 5, 6}, sub.AsSlice())
+	assert.Equal(t, []int{0, 10, 20, 5, 6, 3, 4}, l.AsSlice())
+
+	require.NoError(t, sub.Add(0, 7))
+	assert.Equal(t, []int{7, 10, 20, 5, 6}, sub.AsSlice())
+	assert.Equal(t, []int{0, 7, 10, 20, 5, 6, 3, 4}, l.AsSlice())
+
+	val, err = sub.Delete(4)
+	require.NoError(t, err)
+	assert.Equal(t, 6, val)
+	assert.Equal(t, []int{7, 10, 20, 5}, sub.AsSlice())
+	assert.Equal(t, []int{0, 7, 10, 20, 5, 3, 4}, l.AsSlice())
+
+	vals := make([]int, 0, sub.Len())
+	err = sub.Range(func(index int, t int) error {
+		vals = append(vals, t)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{7, 10, 20, 5}, vals)
+
+	// 下标按照视图计算
+	_, err = sub.Get(4)
+	assert.EqualError(t, err, "ekit: 下标超出范围，长度 4, 下标 4")
+	assert.EqualError(t, sub.Set(-1, 1), "ekit: 下标超出范围，长度 4, 下标 -1")
+	assert.EqualError(t, sub.Add(5, 1), "ekit: 下标超出范围，长度 4, 下标 5")
+	_, err = sub.Delete(4)
+	assert.EqualError(t, err, "ekit: 下标超出范围，长度 4, 下标 4")
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import (
+	"encoding/json"
+
+	"github.com/ecodeclub/ekit/internal/codec"
+)
+
+var (
+	_ json.Marshaler   = (*ArrayList[any])(nil)
+	_ json.Unmarshaler = (*ArrayList[any])(nil)
+	_ json.Marshaler   = (*LinkedList[any])(nil)
+	_ json.Unmarshaler = (*LinkedList[any])(nil)
+)
+
+// MarshalJSON 编码成 JSON 数组
+func (a *ArrayList[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.AsSlice())
+}
+
+// UnmarshalJSON 解码 JSON 数组，原有的元素会被清空
+func (a *ArrayList[T]) UnmarshalJSON(data []byte) error {
+	if codec.IsJSONNull(data) {
+		return nil
+	}
+	var vals []T
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return err
+	}
+	a.reset(vals)
+	return nil
+}
+
+// MarshalBinary 使用 gob 编码
+func (a *ArrayList[T]) MarshalBinary() ([]byte, error) {
+	return codec.GobEncode(a.AsSlice())
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的结果，原有的元素会被清空
+func (a *ArrayList[T]) UnmarshalBinary(data []byte) error {
+	var vals []T
+	if err := codec.GobDecode(data, &vals); err != nil {
+		return err
+	}
+	a.reset(vals)
+	return nil
+}
+
+func (a *ArrayList[T]) reset(vals []T) {
+	if vals == nil {
+		vals = make([]T, 0)
+	}
+	a.vals = vals
+}
+
+// MarshalJSON 编码成 JSON 数组
+func (l *LinkedList[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.asSlice())
+}
+
+// UnmarshalJSON 解码 JSON 数组，原有的元素会被清空
+// 零值的 LinkedList 也可以解码
+func (l *LinkedList[T]) UnmarshalJSON(data []byte) error {
+	if codec.IsJSONNull(data) {
+		return nil
+	}
+	var vals []T
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return err
+	}
+	l.reset(vals)
+	return nil
+}
+
+// MarshalBinary 使用 gob 编码
+func (l *LinkedList[T]) MarshalBinary() ([]byte, error) {
+	return codec.GobEncode(l.asSlice())
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的结果，原有的元素会被清空
+func (l *LinkedList[T]) UnmarshalBinary(data []byte) error {
+	var vals []T
+	if err := codec.GobDecode(data, &vals); err != nil {
+		return err
+	}
+	l.reset(vals)
+	return nil
+}
+
+// asSlice 零值的 LinkedList 没有哨兵结点，不能直接调用 AsSlice
+func (l *LinkedList[T]) asSlice() []T {
+	if l.head == nil {
+		return []T{}
+	}
+	return l.AsSlice()
+}
+
+func (l *LinkedList[T]) reset(vals []T) {
+	*l = *NewLinkedListOf[T](vals)
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList_JSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		list     func() List[int]
+		decoded  func() List[int]
+		wantData string
+		wantVals []int
+	}{
+		{
+			name: "array list",
+			list: func() List[int] {
+				return NewArrayListOf[int]([]int{3, 1, 2})
+			},
+			decoded: func() List[int] {
+				return NewArrayListOf[int]([]int{100})
+			},
+			wantData: `[3,1,2]`,
+			wantVals: []int{3, 1, 2},
+		},
+		{
+			name: "empty array list",
+			list: func() List[int] {
+				return &ArrayList[int]{}
+			},
+			decoded: func() List[int] {
+				return &ArrayList[int]{}
+			},
+			wantData: `[]`,
+			wantVals: []int{},
+		},
+		{
+			name: "linked list",
+			list: func() List[int] {
+				return NewLinkedListOf[int]([]int{3, 1, 2})
+			},
+			decoded: func() List[int] {
+				return NewLinkedListOf[int]([]int{100})
+			},
+			wantData: `[3,1,2]`,
+			wantVals: []int{3, 1, 2},
+		},
+		{
+			name: "zero linked list",
+			list: func() List[int] {
+				return &LinkedList[int]{}
+			},
+			decoded: func() List[int] {
+				return &LinkedList[int]{}
+			},
+			wantData: `[]`,
+			wantVals: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := tc.list()
+			data, err := json.Marshal(l)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantData, string(data))
+
+			decoded := tc.decoded()
+			require.NoError(t, json.Unmarshal(data, decoded))
+			assert.Equal(t, tc.wantVals, decoded.AsSlice())
+			assert.Equal(t, len(tc.wantVals), decoded.Len())
+			// 解码之后依旧可以正常使用
+			require.NoError(t, decoded.Append(4))
+			assert.Equal(t, append(tc.wantVals, 4), decoded.AsSlice())
+
+			bin, err := l.(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+			require.NoError(t, err)
+			decoded = tc.decoded()
+			err = decoded.(interface{ UnmarshalBinary([]byte) error }).UnmarshalBinary(bin)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantVals, decoded.AsSlice())
+		})
+	}
+}
+
+func TestList_UnmarshalJSON(t *testing.T) {
+	a := NewArrayListOf[int]([]int{1})
+	require.NoError(t, json.Unmarshal([]byte(`null`), a))
+	assert.Equal(t, []int{1}, a.AsSlice())
+	var typeErr *json.UnmarshalTypeError
+	assert.ErrorAs(t, json.Unmarshal([]byte(`{"a":1}`), a), &typeErr)
+	assert.Error(t, a.UnmarshalBinary([]byte("invalid")))
+
+	l := NewLinkedListOf[int]([]int{1})
+	require.NoError(t, json.Unmarshal([]byte(`null`), l))
+	assert.Equal(t, []int{1}, l.AsSlice())
+	assert.ErrorAs(t, json.Unmarshal([]byte(`{"a":1}`), l), &typeErr)
+	assert.Error(t, l.UnmarshalBinary([]byte("invalid")))
+
+	// 作为结构体的字段
+	type response struct {
+		Ids *LinkedList[int] `json:"ids"`
+	}
+	var resp response
+	require.NoError(t, json.Unmarshal([]byte(`{"ids":[1,2,3]}`), &resp))
+	assert.Equal(t, []int{1, 2, 3}, resp.Ids.AsSlice())
+}
@@ -80,3 +80,10 @@ func (c *ConcurrentList[T]) AsSlice() []T {
 	defer c.lock.RUnlock()
 	return c.List.AsSlice()
 }
+
+// Iterator 返回一个快照迭代器
+// 迭代的是调用 Iterator 那一刻的内容，迭代的过程中不会持有锁，其它 goroutine 可以继续修改 List。
+// 快照迭代器不支持 Remove 和 Set，调用它们会返回 ErrIteratorUnsupported
+func (c *ConcurrentList[T]) Iterator() Iterator[T] {
+	return newSnapshotIterator[T](c.AsSlice())
+}
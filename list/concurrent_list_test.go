@@ -419,3 +419,23 @@ func newConcurrentListOfSlice[T any](ts []T) *ConcurrentList[T] {
 	var list List[T] = NewArrayListOf(ts)
 	return &ConcurrentList[T]{List: list}
 }
+
+func TestConcurrentList_Iterator(t *testing.T) {
+	list := &ConcurrentList[int]{
+		List: NewLinkedListOf[int]([]int{1, 2, 3}),
+	}
+	it := list.Iterator()
+	// 迭代的过程中修改，不影响迭代器
+	assert.NoError(t, list.Append(4))
+	_, err := list.Delete(0)
+	assert.NoError(t, err)
+
+	vals := make([]int, 0, 3)
+	for it.Next() {
+		vals = append(vals, it.Value())
+		assert.Equal(t, ErrIteratorUnsupported, it.Remove())
+		assert.Equal(t, ErrIteratorUnsupported, it.Set(10))
+	}
+	assert.Equal(t, []int{1, 2, 3}, vals)
+	assert.Equal(t, []int{2, 3, 4}, list.AsSlice())
+}
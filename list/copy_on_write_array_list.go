@@ -146,3 +146,11 @@ func (a *CopyOnWriteArrayList[T]) AsSlice() []T {
 	copy(res, a.vals)
 	return res
 }
+
+// Iterator 返回一个快照迭代器
+// 迭代的是调用 Iterator 那一刻的内容，不会受到后续修改的影响，也不需要加锁。
+// 快照迭代器不支持 Remove 和 Set，调用它们会返回 ErrIteratorUnsupported
+func (a *CopyOnWriteArrayList[T]) Iterator() Iterator[T] {
+	// 每次修改都会创建新的切片，所以这里不需要复制
+	return newSnapshotIterator[T](a.vals)
+}
@@ -392,3 +392,24 @@ func TestCopyOnWriteArrayList_Set(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyOnWriteArrayList_Iterator(t *testing.T) {
+	list := NewCopyOnWriteArrayListOf[int]([]int{1, 2, 3})
+	it := list.Iterator()
+	assert.Equal(t, 0, it.Value())
+	// 迭代的过程中修改，不影响迭代器
+	assert.NoError(t, list.Append(4))
+	_, err := list.Delete(0)
+	assert.NoError(t, err)
+	assert.NoError(t, list.Set(0, 20))
+
+	vals := make([]int, 0, 3)
+	for it.Next() {
+		vals = append(vals, it.Value())
+		assert.Equal(t, ErrIteratorUnsupported, it.Remove())
+		assert.Equal(t, ErrIteratorUnsupported, it.Set(10))
+	}
+	assert.Equal(t, []int{1, 2, 3}, vals)
+	assert.Equal(t, 0, it.Value())
+	assert.Equal(t, []int{20, 3, 4}, list.AsSlice())
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import "errors"
+
+// errStopRange 用于提前结束 Range
+var errStopRange = errors.New("stop range")
+
+// IndexOf 返回第一个和 t 相等的元素的下标，不存在的时候返回 -1
+func IndexOf[T any](l List[T], t T, equal func(src, dst T) bool) int {
+	res := -1
+	_ = l.Range(func(index int, val T) error {
+		if equal(val, t) {
+			res = index
+			return errStopRange
+		}
+		return nil
+	})
+	return res
+}
+
+// LastIndexOf 返回最后一个和 t 相等的元素的下标，不存在的时候返回 -1
+func LastIndexOf[T any](l List[T], t T, equal func(src, dst T) bool) int {
+	res := -1
+	_ = l.Range(func(index int, val T) error {
+		if equal(val, t) {
+			res = index
+		}
+		return nil
+	})
+	return res
+}
+
+// Contains 判断 l 中是否存在和 t 相等的元素
+func Contains[T any](l List[T], t T, equal func(src, dst T) bool) bool {
+	return IndexOf[T](l, t, equal) >= 0
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexOf(t *testing.T) {
+	equal := func(src, dst int) bool {
+		return src == dst
+	}
+	testCases := []struct {
+		name         string
+		list         List[int]
+		target       int
+		wantIndex    int
+		wantLast     int
+		wantContains bool
+	}{
+		{
+			name:      "empty",
+			list:      NewArrayList[int](0),
+			target:    1,
+			wantIndex: -1,
+			wantLast:  -1,
+		},
+		{
+			name:         "found once",
+			list:         NewLinkedListOf[int]([]int{1, 2, 3}),
+			target:       2,
+			wantIndex:    1,
+			wantLast:     1,
+			wantContains: true,
+		},
+		{
+			name:         "found multiple",
+			list:         NewArrayListOf[int]([]int{2, 1, 2, 3, 2}),
+			target:       2,
+			wantIndex:    0,
+			wantLast:     4,
+			wantContains: true,
+		},
+		{
+			name:      "not found",
+			list:      NewCopyOnWriteArrayListOf[int]([]int{1, 2, 3}),
+			target:    4,
+			wantIndex: -1,
+			wantLast:  -1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantIndex, IndexOf[int](tc.list, tc.target, equal))
+			assert.Equal(t, tc.wantLast, LastIndexOf[int](tc.list, tc.target, equal))
+			assert.Equal(t, tc.wantContains, Contains[int](tc.list, tc.target, equal))
+		})
+	}
+}
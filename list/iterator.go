@@ -0,0 +1,84 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import "errors"
+
+var (
+	// ErrIteratorNoElement 迭代器没有指向任何元素
+	// 也就是还没有调用 Next，或者 Next 已经返回了 false，或者当前元素已经被删除了
+	ErrIteratorNoElement = errors.New("ekit: 迭代器没有指向任何元素")
+	// ErrIteratorUnsupported 迭代器不支持该操作，例如快照迭代器不支持修改
+	ErrIteratorUnsupported = errors.New("ekit: 迭代器不支持该操作")
+)
+
+// Iterator 迭代器
+// 典型的用法是：
+//
+//	for it := l.Iterator(); it.Next(); {
+//		v := it.Value()
+//	}
+//
+// 除非特别说明，在迭代的过程中，除了通过迭代器本身的 Remove 和 Set，
+// 不能以其它方式修改 List，否则迭代的结果是未定义的
+type Iterator[T any] interface {
+	// Next 移动到下一个元素，没有更多元素的时候返回 false
+	Next() bool
+	// Value 返回当前元素，迭代器没有指向任何元素的时候返回零值
+	Value() T
+	// Remove 删除当前元素，删除之后迭代器不再指向任何元素，直到下一次调用 Next
+	Remove() error
+	// Set 修改当前元素的值
+	Set(t T) error
+}
+
+// snapshotIterator 在快照上迭代，不支持修改
+type snapshotIterator[T any] struct {
+	vals []T
+	// cur 当前元素的下标，-1 表示没有指向任何元素
+	cur int
+	// next 下一个元素的下标
+	next int
+}
+
+func newSnapshotIterator[T any](vals []T) *snapshotIterator[T] {
+	return &snapshotIterator[T]{vals: vals, cur: -1}
+}
+
+func (s *snapshotIterator[T]) Next() bool {
+	if s.next >= len(s.vals) {
+		s.cur = -1
+		return false
+	}
+	s.cur = s.next
+	s.next++
+	return true
+}
+
+func (s *snapshotIterator[T]) Value() T {
+	if s.cur < 0 {
+		var t T
+		return t
+	}
+	return s.vals[s.cur]
+}
+
+func (s *snapshotIterator[T]) Remove() error {
+	return ErrIteratorUnsupported
+}
+
+func (s *snapshotIterator[T]) Set(t T) error {
+	return ErrIteratorUnsupported
+}
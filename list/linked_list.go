@@ -161,3 +161,66 @@ func (l *LinkedList[T]) AsSlice() []T {
 	}
 	return slice
 }
+
+// Iterator 返回一个从头到尾的迭代器
+func (l *LinkedList[T]) Iterator() Iterator[T] {
+	return &linkedListIterator[T]{list: l, next: l.head.next}
+}
+
+// ReverseIterator 返回一个从尾到头的迭代器
+func (l *LinkedList[T]) ReverseIterator() Iterator[T] {
+	return &linkedListIterator[T]{list: l, next: l.tail.prev, reverse: true}
+}
+
+type linkedListIterator[T any] struct {
+	list *LinkedList[T]
+	// cur 当前结点，nil 表示没有指向任何元素
+	cur *node[T]
+	// next 下一个要访问的结点
+	next    *node[T]
+	reverse bool
+}
+
+func (it *linkedListIterator[T]) Next() bool {
+	// head 和 tail 都是哨兵，走到哨兵就意味着结束了
+	if it.next == it.list.head || it.next == it.list.tail {
+		it.cur = nil
+		return false
+	}
+	it.cur = it.next
+	if it.reverse {
+		it.next = it.cur.prev
+	} else {
+		it.next = it.cur.next
+	}
+	return true
+}
+
+func (it *linkedListIterator[T]) Value() T {
+	if it.cur == nil {
+		var t T
+		return t
+	}
+	return it.cur.val
+}
+
+func (it *linkedListIterator[T]) Remove() error {
+	if it.cur == nil {
+		return ErrIteratorNoElement
+	}
+	cur := it.cur
+	cur.prev.next = cur.next
+	cur.next.prev = cur.prev
+	cur.prev, cur.next = nil, nil
+	it.list.length--
+	it.cur = nil
+	return nil
+}
+
+func (it *linkedListIterator[T]) Set(t T) error {
+	if it.cur == nil {
+		return ErrIteratorNoElement
+	}
+	it.cur.val = t
+	return nil
+}
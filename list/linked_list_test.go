@@ -511,3 +511,90 @@ func BenchmarkLinkedList_Get(b *testing.B) {
 		_, _ = l.Get(i)
 	}
 }
+
+func TestLinkedList_Iterator(t *testing.T) {
+	testCases := []struct {
+		name      string
+		list      *LinkedList[int]
+		reverse   bool
+		wantVals  []int
+		wantSlice []int
+	}{
+		{
+			name:      "empty",
+			list:      NewLinkedList[int](),
+			wantVals:  []int{},
+			wantSlice: []int{},
+		},
+		{
+			name:      "empty reverse",
+			list:      NewLinkedList[int](),
+			reverse:   true,
+			wantVals:  []int{},
+			wantSlice: []int{},
+		},
+		{
+			name:      "remove even and set odd",
+			list:      NewLinkedListOf[int]([]int{1, 2, 3, 4, 4, 5}),
+			wantVals:  []int{1, 2, 3, 4, 4, 5},
+			wantSlice: []int{10, 30, 50},
+		},
+		{
+			name:      "reverse remove even and set odd",
+			list:      NewLinkedListOf[int]([]int{1, 2, 3, 4, 4, 5}),
+			reverse:   true,
+			wantVals:  []int{5, 4, 4, 3, 2, 1},
+			wantSlice: []int{10, 30, 50},
+		},
+		{
+			name:      "remove all",
+			list:      NewLinkedListOf[int]([]int{2, 4, 6}),
+			wantVals:  []int{2, 4, 6},
+			wantSlice: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			it := tc.list.Iterator()
+			if tc.reverse {
+				it = tc.list.ReverseIterator()
+			}
+			vals := make([]int, 0, tc.list.Len())
+			for it.Next() {
+				v := it.Value()
+				vals = append(vals, v)
+				if v%2 == 0 {
+					assert.NoError(t, it.Remove())
+					continue
+				}
+				assert.NoError(t, it.Set(v*10))
+			}
+			assert.Equal(t, tc.wantVals, vals)
+			assert.Equal(t, tc.wantSlice, tc.list.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), tc.list.Len())
+			// 删除之后链表依旧是完整的
+			assert.NoError(t, tc.list.Append(7))
+			assert.NoError(t, tc.list.Add(0, 6))
+			assert.Equal(t, append(append([]int{6}, tc.wantSlice...), 7), tc.list.AsSlice())
+		})
+	}
+}
+
+func TestLinkedList_Iterator_NoElement(t *testing.T) {
+	list := NewLinkedListOf[int]([]int{1})
+	it := list.ReverseIterator()
+	// 还没有调用 Next
+	assert.Equal(t, 0, it.Value())
+	assert.Equal(t, ErrIteratorNoElement, it.Remove())
+	assert.Equal(t, ErrIteratorNoElement, it.Set(2))
+
+	assert.True(t, it.Next())
+	assert.NoError(t, it.Remove())
+	// 已经删除了
+	assert.Equal(t, 0, it.Value())
+	assert.Equal(t, ErrIteratorNoElement, it.Remove())
+	assert.Equal(t, ErrIteratorNoElement, it.Set(2))
+
+	assert.False(t, it.Next())
+	assert.Equal(t, ErrIteratorNoElement, it.Remove())
+}
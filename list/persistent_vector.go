@@ -0,0 +1,260 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import "github.com/ecodeclub/ekit/internal/errs"
+
+const (
+	vectorBits  = 5
+	vectorWidth = 1 << vectorBits
+	vectorMask  = vectorWidth - 1
+)
+
+// PersistentVector 不可变的列表，基于 32 叉的位分区字典树（bit-partitioned trie）实现，
+// 和 Clojure 的 PersistentVector 一样。
+// 所有的修改操作都不会修改原本的 PersistentVector，而是返回一个新的版本，
+// 新旧版本之间共享没有变化的结点，所以修改只需要复制 O(log32(n)) 个结点，
+// 而不是像 CopyOnWriteArrayList 那样复制全部元素。
+// 因为不可变，所以可以在多个 goroutine 之间安全地共享，而不需要加锁。
+// PersistentVector 的零值不可用，必须通过 NewPersistentVector 创建
+type PersistentVector[T any] struct {
+	count int
+	// shift 根结点所在的层需要右移多少位，叶子结点是 0
+	shift uint
+	root  *vectorNode[T]
+	// tail 最后不足 32 个的元素单独存放，这样 Append 大多数时候只需要复制 tail
+	tail []T
+}
+
+type vectorNode[T any] struct {
+	// children 非叶子结点的子结点
+	children []*vectorNode[T]
+	// vals 叶子结点的元素
+	vals []T
+}
+
+// NewPersistentVector 创建一个包含 ts 的 PersistentVector
+func NewPersistentVector[T any](ts ...T) *PersistentVector[T] {
+	v := &PersistentVector[T]{
+		shift: vectorBits,
+		root:  &vectorNode[T]{},
+		tail:  []T{},
+	}
+	return v.Append(ts...)
+}
+
+// Get 返回对应下标的元素，时间复杂度是 O(log32(n))
+func (v *PersistentVector[T]) Get(index int) (T, error) {
+	if index < 0 || index >= v.count {
+		var t T
+		return t, errs.NewErrIndexOutOfRange(v.count, index)
+	}
+	return v.leafFor(index)[index&vectorMask], nil
+}
+
+// Append 返回一个在末尾追加了 ts 的新版本
+func (v *PersistentVector[T]) Append(ts ...T) *PersistentVector[T] {
+	res := v
+	for _, t := range ts {
+		res = res.append(t)
+	}
+	return res
+}
+
+// Set 返回一个 index 位置被替换为 t 的新版本
+func (v *PersistentVector[T]) Set(index int, t T) (*PersistentVector[T], error) {
+	if index < 0 || index >= v.count {
+		return nil, errs.NewErrIndexOutOfRange(v.count, index)
+	}
+	res := *v
+	if index >= v.tailOffset() {
+		tail := make([]T, len(v.tail))
+		copy(tail, v.tail)
+		tail[index&vectorMask] = t
+		res.tail = tail
+		return &res, nil
+	}
+	res.root = v.set(v.shift, v.root, index, t)
+	return &res, nil
+}
+
+// Pop 返回一个删除了最后一个元素的新版本，以及被删除的元素
+// 没有元素的时候返回错误
+func (v *PersistentVector[T]) Pop() (*PersistentVector[T], T, error) {
+	if v.count == 0 {
+		var t T
+		return nil, t, errs.NewErrIndexOutOfRange(0, -1)
+	}
+	last := v.tail[len(v.tail)-1]
+	if v.count == 1 {
+		return NewPersistentVector[T](), last, nil
+	}
+	res := *v
+	res.count--
+	if len(v.tail) > 1 {
+		// 后续的 Append 总是会复制 tail，所以这里可以直接共享
+		res.tail = v.tail[:len(v.tail)-1]
+		return &res, last, nil
+	}
+	// tail 空了，把树中最后一个叶子结点拿出来作为新的 tail
+	res.tail = v.leafFor(v.count - 2)
+	root := v.popTail(v.shift, v.root)
+	if root == nil {
+		root = &vectorNode[T]{}
+	}
+	if v.shift > vectorBits && len(root.children) == 1 {
+		root = root.children[0]
+		res.shift -= vectorBits
+	}
+	res.root = root
+	return &res, last, nil
+}
+
+func (v *PersistentVector[T]) Len() int {
+	return v.count
+}
+
+// Range 遍历所有元素
+func (v *PersistentVector[T]) Range(fn func(index int, t T) error) error {
+	for i := 0; i < v.count; i += vectorWidth {
+		leaf := v.leafFor(i)
+		for j, t := range leaf {
+			if err := fn(i+j, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AsSlice 将 PersistentVector 转化为一个切片
+func (v *PersistentVector[T]) AsSlice() []T {
+	res := make([]T, 0, v.count)
+	for i := 0; i < v.count; i += vectorWidth {
+		res = append(res, v.leafFor(i)...)
+	}
+	return res
+}
+
+// tailOffset 返回 tail 中第一个元素的下标
+func (v *PersistentVector[T]) tailOffset() int {
+	if v.count < vectorWidth {
+		return 0
+	}
+	return ((v.count - 1) >> vectorBits) << vectorBits
+}
+
+// leafFor 返回包含 index 的叶子结点，可能是 tail
+func (v *PersistentVector[T]) leafFor(index int) []T {
+	if index >= v.tailOffset() {
+		return v.tail
+	}
+	node := v.root
+	for level := v.shift; level > 0; level -= vectorBits {
+		node = node.children[(index>>level)&vectorMask]
+	}
+	return node.vals
+}
+
+func (v *PersistentVector[T]) append(t T) *PersistentVector[T] {
+	res := *v
+	res.count++
+	if len(v.tail) < vectorWidth {
+		tail := make([]T, len(v.tail), len(v.tail)+1)
+		copy(tail, v.tail)
+		res.tail = append(tail, t)
+		return &res
+	}
+	// tail 满了，放进树里
+	leaf := &vectorNode[T]{vals: v.tail}
+	if (v.count >> vectorBits) > (1 << v.shift) {
+		// 根结点也满了，树长高一层
+		res.root = &vectorNode[T]{
+			children: []*vectorNode[T]{v.root, newVectorPath[T](v.shift, leaf)},
+		}
+		res.shift += vectorBits
+	} else {
+		res.root = v.pushTail(v.shift, v.root, leaf)
+	}
+	res.tail = []T{t}
+	return &res
+}
+
+// pushTail 将叶子结点放到树的最右边，返回复制之后的 parent
+func (v *PersistentVector[T]) pushTail(level uint, parent *vectorNode[T], leaf *vectorNode[T]) *vectorNode[T] {
+	idx := ((v.count - 1) >> level) & vectorMask
+	children := make([]*vectorNode[T], len(parent.children), idx+1)
+	copy(children, parent.children)
+	var child *vectorNode[T]
+	if level == vectorBits {
+		child = leaf
+	} else if idx < len(parent.children) {
+		child = v.pushTail(level-vectorBits, parent.children[idx], leaf)
+	} else {
+		child = newVectorPath[T](level-vectorBits, leaf)
+	}
+	if idx < len(children) {
+		children[idx] = child
+	} else {
+		children = append(children, child)
+	}
+	return &vectorNode[T]{children: children}
+}
+
+// popTail 删除树最右边的叶子结点，返回复制之后的 node，node 空了的时候返回 nil
+func (v *PersistentVector[T]) popTail(level uint, node *vectorNode[T]) *vectorNode[T] {
+	idx := ((v.count - 2) >> level) & vectorMask
+	if level > vectorBits {
+		child := v.popTail(level-vectorBits, node.children[idx])
+		if child == nil && idx == 0 {
+			return nil
+		}
+		children := make([]*vectorNode[T], idx, idx+1)
+		copy(children, node.children[:idx])
+		if child != nil {
+			children = append(children, child)
+		}
+		return &vectorNode[T]{children: children}
+	}
+	if idx == 0 {
+		return nil
+	}
+	children := make([]*vectorNode[T], idx)
+	copy(children, node.children[:idx])
+	return &vectorNode[T]{children: children}
+}
+
+// set 复制从根结点到 index 所在叶子结点的路径
+func (v *PersistentVector[T]) set(level uint, node *vectorNode[T], index int, t T) *vectorNode[T] {
+	if level == 0 {
+		vals := make([]T, len(node.vals))
+		copy(vals, node.vals)
+		vals[index&vectorMask] = t
+		return &vectorNode[T]{vals: vals}
+	}
+	children := make([]*vectorNode[T], len(node.children))
+	copy(children, node.children)
+	idx := (index >> level) & vectorMask
+	children[idx] = v.set(level-vectorBits, node.children[idx], index, t)
+	return &vectorNode[T]{children: children}
+}
+
+// newVectorPath 创建一条从 level 层到叶子结点的路径
+func newVectorPath[T any](level uint, leaf *vectorNode[T]) *vectorNode[T] {
+	if level == 0 {
+		return leaf
+	}
+	return &vectorNode[T]{children: []*vectorNode[T]{newVectorPath[T](level-vectorBits, leaf)}}
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersistentVector_Append(t *testing.T) {
+	// 覆盖只有 tail、一层、两层和三层的情况
+	testCases := []struct {
+		name string
+		n    int
+	}{
+		{name: "empty", n: 0},
+		{name: "tail only", n: 31},
+		{name: "one level", n: 32*32 + 1},
+		{name: "two levels", n: 32*32*32 + 33},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := make([]int, 0, tc.n)
+			v := NewPersistentVector[int]()
+			for i := 0; i < tc.n; i++ {
+				v = v.Append(i)
+				want = append(want, i)
+			}
+			assert.Equal(t, tc.n, v.Len())
+			assert.Equal(t, want, v.AsSlice())
+			for i := 0; i < tc.n; i++ {
+				val, err := v.Get(i)
+				require.NoError(t, err)
+				require.Equal(t, i, val)
+			}
+		})
+	}
+}
+
+func TestPersistentVector_Get(t *testing.T) {
+	v := NewPersistentVector[int](1, 2, 3)
+	_, err := v.Get(3)
+	assert.Equal(t, errors.New("ekit: 下标超出范围，长度 3, 下标 3"), err)
+	_, err = v.Get(-1)
+	assert.Equal(t, errors.New("ekit: 下标超出范围，长度 3, 下标 -1"), err)
+}
+
+func TestPersistentVector_Set(t *testing.T) {
+	v1 := NewPersistentVector[int](makeRange(100)...)
+	v2, err := v1.Set(10, -10)
+	require.NoError(t, err)
+	v3, err := v2.Set(99, -99)
+	require.NoError(t, err)
+
+	// 旧版本不受影响
+	assert.Equal(t, makeRange(100), v1.AsSlice())
+	want := makeRange(100)
+	want[10] = -10
+	assert.Equal(t, want, v2.AsSlice())
+	want[99] = -99
+	assert.Equal(t, want, v3.AsSlice())
+
+	_, err = v1.Set(100, 1)
+	assert.Equal(t, errors.New("ekit: 下标超出范围，长度 100, 下标 100"), err)
+}
+
+func TestPersistentVector_Pop(t *testing.T) {
+	n := 32*32 + 70
+	versions := make([]*PersistentVector[int], 0, n+1)
+	v := NewPersistentVector[int](makeRange(n)...)
+	versions = append(versions, v)
+	for i := n - 1; i >= 0; i-- {
+		var val int
+		var err error
+		v, val, err = v.Pop()
+		require.NoError(t, err)
+		require.Equal(t, i, val)
+		require.Equal(t, makeRange(i), v.AsSlice())
+		versions = append(versions, v)
+	}
+	_, _, err := v.Pop()
+	assert.Error(t, err)
+
+	// 所有的旧版本都不受影响
+	for i, version := range versions {
+		assert.Equal(t, makeRange(n-i), version.AsSlice())
+	}
+
+	// Pop 之后再 Append
+	v = versions[40]
+	v = v.Append(-1)
+	want := append(makeRange(n-40), -1)
+	assert.Equal(t, want, v.AsSlice())
+	assert.Equal(t, makeRange(n-39), versions[39].AsSlice())
+}
+
+// TestPersistentVector_Random 随机操作，和切片的结果进行对比
+func TestPersistentVector_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	v := NewPersistentVector[int]()
+	want := make([]int, 0)
+	for i := 0; i < 20000; i++ {
+		switch op := r.Intn(10); {
+		case op < 6 || len(want) == 0:
+			v = v.Append(i)
+			want = append(want, i)
+		case op < 8:
+			index := r.Intn(len(want))
+			var err error
+			v, err = v.Set(index, -i)
+			require.NoError(t, err)
+			want[index] = -i
+		default:
+			var val int
+			var err error
+			v, val, err = v.Pop()
+			require.NoError(t, err)
+			require.Equal(t, want[len(want)-1], val)
+			want = want[:len(want)-1]
+		}
+	}
+	assert.Equal(t, len(want), v.Len())
+	assert.Equal(t, want, v.AsSlice())
+}
+
+func TestPersistentVector_Range(t *testing.T) {
+	v := NewPersistentVector[int](makeRange(100)...)
+	vals := make([]int, 0, 100)
+	err := v.Range(func(index int, t int) error {
+		if index == 50 {
+			return errors.New("stop")
+		}
+		vals = append(vals, t)
+		return nil
+	})
+	assert.Equal(t, errors.New("stop"), err)
+	assert.Equal(t, makeRange(50), vals)
+}
+
+func makeRange(n int) []int {
+	res := make([]int, n)
+	for i := range res {
+		res[i] = i
+	}
+	return res
+}
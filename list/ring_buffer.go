@@ -0,0 +1,157 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import "github.com/ecodeclub/ekit/internal/errs"
+
+var (
+	_ List[any] = &RingBuffer[any]{}
+)
+
+// RingBuffer 固定容量的环形缓冲区，适合用于保存最近的 N 个元素
+// 满了之后，新加入的元素会覆盖最旧的元素。
+// 下标都是相对于最旧的元素而言的，也就是说下标 0 永远是最旧的元素，下标 Len()-1 是最新的元素。
+// Append、Get 和 Set 的时间复杂度都是 O(1)，Add 和 Delete 需要移动元素，时间复杂度是 O(n)
+type RingBuffer[T any] struct {
+	vals []T
+	// head 最旧的元素在 vals 中的位置
+	head  int
+	count int
+}
+
+// NewRingBuffer 创建一个容量为 capacity 的 RingBuffer
+// capacity 小于等于 0 的时候，RingBuffer 不会保存任何元素
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &RingBuffer[T]{vals: make([]T, capacity)}
+}
+
+// NewConcurrentRingBuffer 创建一个线程安全的 RingBuffer
+func NewConcurrentRingBuffer[T any](capacity int) *ConcurrentList[T] {
+	return &ConcurrentList[T]{List: NewRingBuffer[T](capacity)}
+}
+
+func (r *RingBuffer[T]) Get(index int) (T, error) {
+	if index < 0 || index >= r.count {
+		var t T
+		return t, errs.NewErrIndexOutOfRange(r.count, index)
+	}
+	return r.vals[r.pos(index)], nil
+}
+
+// Append 在末尾追加元素，满了之后会覆盖最旧的元素
+func (r *RingBuffer[T]) Append(ts ...T) error {
+	if len(r.vals) == 0 {
+		return nil
+	}
+	for _, t := range ts {
+		if r.count < len(r.vals) {
+			r.vals[r.pos(r.count)] = t
+			r.count++
+			continue
+		}
+		r.vals[r.head] = t
+		r.head = r.pos(1)
+	}
+	return nil
+}
+
+// Add 在 index 处插入一个元素
+// 满了的时候，插入之后最旧的元素会被丢弃，
+// 所以在 index 为 0 的时候，新插入的元素本身就是最旧的，会被直接丢弃
+func (r *RingBuffer[T]) Add(index int, t T) error {
+	if index < 0 || index > r.count {
+		return errs.NewErrIndexOutOfRange(r.count, index)
+	}
+	if r.count == len(r.vals) {
+		if index == 0 {
+			return nil
+		}
+		// 丢弃最旧的元素，腾出位置
+		r.head = r.pos(1)
+		r.count--
+		index--
+	}
+	for i := r.count; i > index; i-- {
+		r.vals[r.pos(i)] = r.vals[r.pos(i-1)]
+	}
+	r.vals[r.pos(index)] = t
+	r.count++
+	return nil
+}
+
+func (r *RingBuffer[T]) Set(index int, t T) error {
+	if index < 0 || index >= r.count {
+		return errs.NewErrIndexOutOfRange(r.count, index)
+	}
+	r.vals[r.pos(index)] = t
+	return nil
+}
+
+// Delete 删除 index 处的元素，后面的元素会往前移动
+func (r *RingBuffer[T]) Delete(index int) (T, error) {
+	var zero T
+	if index < 0 || index >= r.count {
+		return zero, errs.NewErrIndexOutOfRange(r.count, index)
+	}
+	res := r.vals[r.pos(index)]
+	for i := index; i < r.count-1; i++ {
+		r.vals[r.pos(i)] = r.vals[r.pos(i+1)]
+	}
+	// 避免内存泄露
+	r.vals[r.pos(r.count-1)] = zero
+	r.count--
+	return res, nil
+}
+
+func (r *RingBuffer[T]) Len() int {
+	return r.count
+}
+
+// Cap 返回固定的容量
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.vals)
+}
+
+// Range 从最旧的元素开始遍历
+func (r *RingBuffer[T]) Range(fn func(index int, t T) error) error {
+	for i := 0; i < r.count; i++ {
+		err := fn(i, r.vals[r.pos(i)])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AsSlice 按照从旧到新的顺序返回所有元素
+func (r *RingBuffer[T]) AsSlice() []T {
+	res := make([]T, r.count)
+	end := r.head + r.count
+	if end > len(r.vals) {
+		end = len(r.vals)
+	}
+	// 先复制 head 之后的部分，再复制绕回来的部分
+	n := copy(res, r.vals[r.head:end])
+	copy(res[n:], r.vals[:r.count-n])
+	return res
+}
+
+// pos 返回下标 index 在 vals 中的位置
+func (r *RingBuffer[T]) pos(index int) int {
+	return (r.head + index) % len(r.vals)
+}
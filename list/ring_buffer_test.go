@@ -0,0 +1,235 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingBuffer_Append(t *testing.T) {
+	testCases := []struct {
+		name      string
+		capacity  int
+		vals      []int
+		wantSlice []int
+	}{
+		{
+			name:      "zero capacity",
+			capacity:  0,
+			vals:      []int{1, 2},
+			wantSlice: []int{},
+		},
+		{
+			name:      "negative capacity",
+			capacity:  -1,
+			vals:      []int{1, 2},
+			wantSlice: []int{},
+		},
+		{
+			name:      "not full",
+			capacity:  3,
+			vals:      []int{1, 2},
+			wantSlice: []int{1, 2},
+		},
+		{
+			name:      "full",
+			capacity:  3,
+			vals:      []int{1, 2, 3},
+			wantSlice: []int{1, 2, 3},
+		},
+		{
+			name:      "overwrite",
+			capacity:  3,
+			vals:      []int{1, 2, 3, 4, 5},
+			wantSlice: []int{3, 4, 5},
+		},
+		{
+			name:      "overwrite many rounds",
+			capacity:  3,
+			vals:      []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			wantSlice: []int{8, 9, 10},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRingBuffer[int](tc.capacity)
+			require.NoError(t, r.Append(tc.vals...))
+			assert.Equal(t, tc.wantSlice, r.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), r.Len())
+			for i, want := range tc.wantSlice {
+				val, err := r.Get(i)
+				require.NoError(t, err)
+				assert.Equal(t, want, val)
+			}
+		})
+	}
+}
+
+func TestRingBuffer_Add(t *testing.T) {
+	testCases := []struct {
+		name      string
+		vals      []int
+		index     int
+		val       int
+		wantSlice []int
+		wantErr   error
+	}{
+		{
+			name:      "empty",
+			index:     0,
+			val:       100,
+			wantSlice: []int{100},
+		},
+		{
+			name:      "middle",
+			vals:      []int{1, 2},
+			index:     1,
+			val:       100,
+			wantSlice: []int{1, 100, 2},
+		},
+		{
+			name:      "full and wrapped",
+			vals:      []int{1, 2, 3, 4, 5},
+			index:     2,
+			val:       100,
+			wantSlice: []int{4, 100, 5},
+		},
+		{
+			name:      "full and append",
+			vals:      []int{1, 2, 3},
+			index:     3,
+			val:       100,
+			wantSlice: []int{2, 3, 100},
+		},
+		{
+			// 新插入的元素是最旧的，直接被丢弃
+			name:      "full and add to head",
+			vals:      []int{1, 2, 3},
+			index:     0,
+			val:       100,
+			wantSlice: []int{1, 2, 3},
+		},
+		{
+			name:      "index out of range",
+			vals:      []int{1, 2},
+			index:     3,
+			wantSlice: []int{1, 2},
+			wantErr:   errors.New("ekit: 下标超出范围，长度 2, 下标 3"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRingBuffer[int](3)
+			require.NoError(t, r.Append(tc.vals...))
+			err := r.Add(tc.index, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, r.AsSlice())
+		})
+	}
+}
+
+func TestRingBuffer_Delete(t *testing.T) {
+	testCases := []struct {
+		name      string
+		vals      []int
+		index     int
+		wantVal   int
+		wantSlice []int
+		wantErr   error
+	}{
+		{
+			name:      "first",
+			vals:      []int{1, 2, 3},
+			index:     0,
+			wantVal:   1,
+			wantSlice: []int{2, 3},
+		},
+		{
+			name:      "wrapped",
+			vals:      []int{1, 2, 3, 4, 5},
+			index:     1,
+			wantVal:   4,
+			wantSlice: []int{3, 5},
+		},
+		{
+			name:      "last",
+			vals:      []int{1, 2, 3, 4},
+			index:     2,
+			wantVal:   4,
+			wantSlice: []int{2, 3},
+		},
+		{
+			name:      "index out of range",
+			vals:      []int{1, 2},
+			index:     2,
+			wantSlice: []int{1, 2},
+			wantErr:   errors.New("ekit: 下标超出范围，长度 2, 下标 2"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRingBuffer[int](3)
+			require.NoError(t, r.Append(tc.vals...))
+			val, err := r.Delete(tc.index)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVal, val)
+			assert.Equal(t, tc.wantSlice, r.AsSlice())
+			// 删除之后依旧可以正常追加
+			require.NoError(t, r.Append(100))
+			assert.Equal(t, append(tc.wantSlice, 100), r.AsSlice())
+		})
+	}
+}
+
+func TestRingBuffer_Set(t *testing.T) {
+	r := NewRingBuffer[int](3)
+	require.NoError(t, r.Append(1, 2, 3, 4))
+	require.NoError(t, r.Set(0, 20))
+	require.NoError(t, r.Set(2, 40))
+	assert.Equal(t, []int{20, 3, 40}, r.AsSlice())
+	assert.Equal(t, errors.New("ekit: 下标超出范围，长度 3, 下标 3"), r.Set(3, 1))
+	_, err := r.Get(-1)
+	assert.Equal(t, errors.New("ekit: 下标超出范围，长度 3, 下标 -1"), err)
+	assert.Equal(t, 3, r.Cap())
+}
+
+func TestRingBuffer_Range(t *testing.T) {
+	r := NewRingBuffer[int](3)
+	require.NoError(t, r.Append(1, 2, 3, 4))
+	vals := make([]int, 0, 3)
+	indexes := make([]int, 0, 3)
+	err := r.Range(func(index int, t int) error {
+		if t == 4 {
+			return errors.New("stop")
+		}
+		indexes = append(indexes, index)
+		vals = append(vals, t)
+		return nil
+	})
+	assert.Equal(t, errors.New("stop"), err)
+	assert.Equal(t, []int{0, 1}, indexes)
+	assert.Equal(t, []int{2, 3}, vals)
+}
+
+func TestNewConcurrentRingBuffer(t *testing.T) {
+	r := NewConcurrentRingBuffer[int](3)
+	require.NoError(t, r.Append(1, 2, 3, 4))
+	assert.Equal(t, []int{2, 3, 4}, r.AsSlice())
+	assert.Equal(t, 3, r.Cap())
+}
@@ -52,3 +52,36 @@ func (sl *SkipList[T]) Insert(Val T) {
 func (sl *SkipList[T]) DeleteElement(target T) bool {
 	return sl.skiplist.DeleteElement(target)
 }
+
+// Rank 返回第一个和 target 相等的元素的排名，排名从 0 开始，不存在的时候返回 -1
+// 时间复杂度是 O(logN)
+func (sl *SkipList[T]) Rank(target T) int {
+	return sl.skiplist.Rank(target)
+}
+
+// GetByRank 返回排名为 rank 的元素，排名从 0 开始
+// 时间复杂度是 O(logN)
+func (sl *SkipList[T]) GetByRank(rank int) (T, error) {
+	return sl.skiplist.Get(rank)
+}
+
+// RangeByRank 返回排名在 [start, stop] 之间的元素，排名从 0 开始
+// 和 Redis 的 ZRANGE 一样，负数表示从末尾开始计算，例如 -1 是最后一个元素
+func (sl *SkipList[T]) RangeByRank(start, stop int) []T {
+	return sl.skiplist.RangeByRank(start, stop)
+}
+
+// RangeByScore 返回在 [min, max] 之间的元素
+func (sl *SkipList[T]) RangeByScore(min, max T) []T {
+	return sl.skiplist.RangeByScore(min, max)
+}
+
+// Floor 返回小于等于 target 的最大的元素，不存在的时候返回 false
+func (sl *SkipList[T]) Floor(target T) (T, bool) {
+	return sl.skiplist.Floor(target)
+}
+
+// Ceiling 返回大于等于 target 的最小的元素，不存在的时候返回 false
+func (sl *SkipList[T]) Ceiling(target T) (T, bool) {
+	return sl.skiplist.Ceiling(target)
+}
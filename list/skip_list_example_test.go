@@ -29,3 +29,16 @@ func ExampleNewSkipList() {
 	// Output:
 	// 123
 }
+
+func ExampleSkipList_RangeByRank() {
+	l := list.NewSkipList[int](ekit.ComparatorRealNumber[int])
+	for _, score := range []int{80, 95, 60, 70} {
+		l.Insert(score)
+	}
+	// 分数最高的两个
+	fmt.Println(l.RangeByRank(-2, -1))
+	fmt.Println(l.Rank(70))
+	// Output:
+	// [80 95]
+	// 1
+}
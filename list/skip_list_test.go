@@ -170,3 +170,31 @@ func TestSkipList_Search(t *testing.T) {
 		})
 	}
 }
+
+func TestSkipList_Rank(t *testing.T) {
+	sl := NewSkipList[int](ekit.ComparatorRealNumber[int])
+	for _, val := range []int{50, 10, 40, 20, 30} {
+		sl.Insert(val)
+	}
+	assert.Equal(t, 0, sl.Rank(10))
+	assert.Equal(t, 4, sl.Rank(50))
+	assert.Equal(t, -1, sl.Rank(25))
+
+	val, err := sl.GetByRank(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 30, val)
+	_, err = sl.GetByRank(5)
+	assert.Error(t, err)
+
+	assert.Equal(t, []int{40, 50}, sl.RangeByRank(-2, -1))
+	assert.Equal(t, []int{20, 30, 40}, sl.RangeByScore(15, 45))
+
+	val, ok := sl.Floor(25)
+	assert.True(t, ok)
+	assert.Equal(t, 20, val)
+	val, ok = sl.Ceiling(25)
+	assert.True(t, ok)
+	assert.Equal(t, 30, val)
+	_, ok = sl.Ceiling(55)
+	assert.False(t, ok)
+}
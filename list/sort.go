@@ -0,0 +1,104 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import (
+	"sort"
+
+	"github.com/ecodeclub/ekit"
+)
+
+// Sort 使用 cmp 对 l 进行原地的稳定排序
+// 对于 ConcurrentList 和 CopyOnWriteArrayList，整个排序的过程是原子的
+func Sort[T any](l List[T], cmp ekit.Comparator[T]) error {
+	switch v := l.(type) {
+	case *ArrayList[T]:
+		sortSlice(v.vals, cmp)
+	case *arraySubList[T]:
+		sortSlice(v.parent.vals[v.offset:v.offset+v.size], cmp)
+	case *CopyOnWriteArrayList[T]:
+		v.mutex.Lock()
+		defer v.mutex.Unlock()
+		vals := make([]T, len(v.vals))
+		copy(vals, v.vals)
+		sortSlice(vals, cmp)
+		v.vals = vals
+	case *ConcurrentList[T]:
+		v.lock.Lock()
+		defer v.lock.Unlock()
+		return Sort[T](v.List, cmp)
+	case *LinkedList[T]:
+		vals := v.AsSlice()
+		sortSlice(vals, cmp)
+		// 通过迭代器回写，避免 Set 带来的 O(n^2)
+		it := v.Iterator()
+		for i := 0; it.Next(); i++ {
+			_ = it.Set(vals[i])
+		}
+	default:
+		vals := l.AsSlice()
+		sortSlice(vals, cmp)
+		for i, val := range vals {
+			if err := l.Set(i, val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// BinarySearch 在已经按照 cmp 升序排列的 l 中查找 target
+// 找到的时候返回第一个等于 target 的元素的下标和 true，
+// 否则返回 target 应该插入的位置和 false。
+// 对于不支持随机访问的 List，例如 LinkedList，时间复杂度是 O(n)
+func BinarySearch[T any](l List[T], target T, cmp ekit.Comparator[T]) (int, bool) {
+	switch v := l.(type) {
+	case *ArrayList[T]:
+		return binarySearchSlice(v.vals, target, cmp)
+	case *CopyOnWriteArrayList[T]:
+		// 在快照上查找
+		return binarySearchSlice(v.vals, target, cmp)
+	case *ConcurrentList[T]:
+		v.lock.RLock()
+		defer v.lock.RUnlock()
+		return BinarySearch[T](v.List, target, cmp)
+	case *LinkedList[T]:
+		return binarySearchSlice(v.AsSlice(), target, cmp)
+	default:
+		return binarySearch(l.Len(), func(i int) T {
+			val, _ := l.Get(i)
+			return val
+		}, target, cmp)
+	}
+}
+
+func sortSlice[T any](vals []T, cmp ekit.Comparator[T]) {
+	sort.SliceStable(vals, func(i, j int) bool {
+		return cmp(vals[i], vals[j]) < 0
+	})
+}
+
+func binarySearchSlice[T any](vals []T, target T, cmp ekit.Comparator[T]) (int, bool) {
+	return binarySearch(len(vals), func(i int) T {
+		return vals[i]
+	}, target, cmp)
+}
+
+func binarySearch[T any](n int, get func(i int) T, target T, cmp ekit.Comparator[T]) (int, bool) {
+	i := sort.Search(n, func(i int) bool {
+		return cmp(get(i), target) >= 0
+	})
+	return i, i < n && cmp(get(i), target) == 0
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import (
+	"testing"
+
+	"github.com/ecodeclub/ekit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSort(t *testing.T) {
+	src := []sortItem{{key: 3, seq: 0}, {key: 1, seq: 1}, {key: 3, seq: 2}, {key: 2, seq: 3}, {key: 1, seq: 4}}
+	// 稳定排序，key 相同的元素保持原来的顺序
+	want := []sortItem{{key: 1, seq: 1}, {key: 1, seq: 4}, {key: 2, seq: 3}, {key: 3, seq: 0}, {key: 3, seq: 2}}
+	testCases := []struct {
+		name string
+		list func() List[sortItem]
+	}{
+		{
+			name: "array list",
+			list: func() List[sortItem] {
+				return NewArrayListOf[sortItem](cloneItems(src))
+			},
+		},
+		{
+			name: "linked list",
+			list: func() List[sortItem] {
+				return NewLinkedListOf[sortItem](src)
+			},
+		},
+		{
+			name: "copy on write array list",
+			list: func() List[sortItem] {
+				return NewCopyOnWriteArrayListOf[sortItem](src)
+			},
+		},
+		{
+			name: "concurrent list",
+			list: func() List[sortItem] {
+				return &ConcurrentList[sortItem]{List: NewLinkedListOf[sortItem](src)}
+			},
+		},
+		{
+			name: "other list",
+			list: func() List[sortItem] {
+				return &otherList[sortItem]{List: NewArrayListOf[sortItem](cloneItems(src))}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := tc.list()
+			require.NoError(t, Sort[sortItem](l, compareSortItem))
+			assert.Equal(t, want, l.AsSlice())
+		})
+	}
+
+	t.Run("sub list", func(t *testing.T) {
+		l := NewArrayListOf[int]([]int{5, 4, 3, 2, 1})
+		sub, err := l.SubList(1, 4)
+		require.NoError(t, err)
+		require.NoError(t, Sort[int](sub, ekit.ComparatorRealNumber[int]))
+		assert.Equal(t, []int{5, 2, 3, 4, 1}, l.AsSlice())
+	})
+}
+
+func TestBinarySearch(t *testing.T) {
+	src := []int{1, 3, 3, 5, 7}
+	lists := map[string]func() List[int]{
+		"array list": func() List[int] {
+			return NewArrayListOf[int](src)
+		},
+		"linked list": func() List[int] {
+			return NewLinkedListOf[int](src)
+		},
+		"copy on write array list": func() List[int] {
+			return NewCopyOnWriteArrayListOf[int](src)
+		},
+		"concurrent list": func() List[int] {
+			return &ConcurrentList[int]{List: NewArrayListOf[int](src)}
+		},
+		"other list": func() List[int] {
+			return &otherList[int]{List: NewArrayListOf[int](src)}
+		},
+	}
+	testCases := []struct {
+		name      string
+		target    int
+		wantIndex int
+		wantFound bool
+	}{
+		{
+			name:      "first",
+			target:    1,
+			wantIndex: 0,
+			wantFound: true,
+		},
+		{
+			name:      "duplicate",
+			target:    3,
+			wantIndex: 1,
+			wantFound: true,
+		},
+		{
+			name:      "last",
+			target:    7,
+			wantIndex: 4,
+			wantFound: true,
+		},
+		{
+			name:      "less than all",
+			target:    0,
+			wantIndex: 0,
+		},
+		{
+			name:      "not found",
+			target:    4,
+			wantIndex: 3,
+		},
+		{
+			name:      "greater than all",
+			target:    8,
+			wantIndex: 5,
+		},
+	}
+	for name, list := range lists {
+		for _, tc := range testCases {
+			t.Run(name+" "+tc.name, func(t *testing.T) {
+				index, found := BinarySearch[int](list(), tc.target, ekit.ComparatorRealNumber[int])
+				assert.Equal(t, tc.wantIndex, index)
+				assert.Equal(t, tc.wantFound, found)
+			})
+		}
+	}
+}
+
+type sortItem struct {
+	key int
+	seq int
+}
+
+func compareSortItem(src, dst sortItem) int {
+	return ekit.ComparatorRealNumber[int](src.key, dst.key)
+}
+
+func cloneItems(src []sortItem) []sortItem {
+	res := make([]sortItem, len(src))
+	copy(res, src)
+	return res
+}
+
+// otherList 用于测试没有特殊处理的 List 实现
+type otherList[T any] struct {
+	List[T]
+}
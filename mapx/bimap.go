@@ -0,0 +1,162 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"errors"
+
+	"github.com/ecodeclub/ekit/bean/option"
+)
+
+// ErrBiMapValueConflict 表示 value 已经和另外一个 key 关联了
+var ErrBiMapValueConflict = errors.New("ekit: value 已经和另外一个 key 关联了")
+
+var _ Map[int, int] = (*BiMap[int, int])(nil)
+
+// BiMap 双向 map，key 和 value 都是唯一的，所以既可以通过 key 找 value，也可以通过 value 找 key
+// 默认情况下，如果 Put 的 value 已经和另外一个 key 关联了，那么会返回 ErrBiMapValueConflict；
+// 通过 BiMapWithForcePut 可以改为强制覆盖，也就是先删除旧的关联关系。
+// BiMap 不是线程安全的
+type BiMap[K comparable, V comparable] struct {
+	forward  map[K]V
+	backward map[V]K
+	force    bool
+	inverse  *BiMap[V, K]
+}
+
+// NewBiMap 创建一个初始容量为 capacity 的 BiMap
+func NewBiMap[K comparable, V comparable](capacity int, opts ...option.Option[BiMap[K, V]]) *BiMap[K, V] {
+	res := &BiMap[K, V]{
+		forward:  make(map[K]V, capacity),
+		backward: make(map[V]K, capacity),
+	}
+	option.Apply(res, opts...)
+	return res
+}
+
+// BiMapWithForcePut 冲突的时候 Put 会强制覆盖，效果和 ForcePut 一样
+func BiMapWithForcePut[K comparable, V comparable]() option.Option[BiMap[K, V]] {
+	return func(b *BiMap[K, V]) {
+		b.force = true
+	}
+}
+
+// Put 放入键值对
+// 如果 key 已经存在，那么会覆盖旧的 value，旧的 value 也就不再和 key 关联了。
+// 如果 val 已经和另外一个 key 关联了，那么按照冲突策略返回 ErrBiMapValueConflict 或者强制覆盖
+func (b *BiMap[K, V]) Put(key K, val V) error {
+	if b.force {
+		b.ForcePut(key, val)
+		return nil
+	}
+	if k, ok := b.backward[val]; ok && k != key {
+		return ErrBiMapValueConflict
+	}
+	b.put(key, val)
+	return nil
+}
+
+// ForcePut 放入键值对，如果 val 已经和另外一个 key 关联了，那么那个 key 会被删除
+func (b *BiMap[K, V]) ForcePut(key K, val V) {
+	if k, ok := b.backward[val]; ok && k != key {
+		delete(b.forward, k)
+	}
+	b.put(key, val)
+}
+
+func (b *BiMap[K, V]) put(key K, val V) {
+	if old, ok := b.forward[key]; ok {
+		delete(b.backward, old)
+	}
+	b.forward[key] = val
+	b.backward[val] = key
+}
+
+// Get 通过 key 找 value
+func (b *BiMap[K, V]) Get(key K) (V, bool) {
+	val, ok := b.forward[key]
+	return val, ok
+}
+
+// GetByValue 通过 value 找 key
+func (b *BiMap[K, V]) GetByValue(val V) (K, bool) {
+	key, ok := b.backward[val]
+	return key, ok
+}
+
+// ContainsValue 判断 val 是否存在
+func (b *BiMap[K, V]) ContainsValue(val V) bool {
+	_, ok := b.backward[val]
+	return ok
+}
+
+// Delete 通过 key 删除键值对
+func (b *BiMap[K, V]) Delete(key K) (V, bool) {
+	val, ok := b.forward[key]
+	if ok {
+		delete(b.forward, key)
+		delete(b.backward, val)
+	}
+	return val, ok
+}
+
+// DeleteByValue 通过 value 删除键值对
+func (b *BiMap[K, V]) DeleteByValue(val V) (K, bool) {
+	key, ok := b.backward[val]
+	if ok {
+		delete(b.backward, val)
+		delete(b.forward, key)
+	}
+	return key, ok
+}
+
+// Keys 返回的 key 是随机的
+func (b *BiMap[K, V]) Keys() []K {
+	return Keys[K, V](b.forward)
+}
+
+// Values 返回的 value 是随机的
+func (b *BiMap[K, V]) Values() []V {
+	return Values[K, V](b.forward)
+}
+
+func (b *BiMap[K, V]) Len() int64 {
+	return int64(len(b.forward))
+}
+
+// Iterate 按照随机顺序遍历
+func (b *BiMap[K, V]) Iterate(cb func(key K, val V) bool) {
+	for k, v := range b.forward {
+		if !cb(k, v) {
+			break
+		}
+	}
+}
+
+// Inverse 返回 value 到 key 的视图
+// 视图和原本的 BiMap 共享数据，对其中一个的修改会立刻体现在另外一个上，
+// 冲突策略也和原本的 BiMap 一样。
+// 对视图再调用 Inverse 会返回原本的 BiMap
+func (b *BiMap[K, V]) Inverse() *BiMap[V, K] {
+	if b.inverse == nil {
+		b.inverse = &BiMap[V, K]{
+			forward:  b.backward,
+			backward: b.forward,
+			force:    b.force,
+			inverse:  b,
+		}
+	}
+	return b.inverse
+}
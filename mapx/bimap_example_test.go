@@ -0,0 +1,39 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx_test
+
+import (
+	"fmt"
+
+	"github.com/ecodeclub/ekit/mapx"
+)
+
+func ExampleBiMap_Inverse() {
+	codes := mapx.NewBiMap[int, string](0)
+	_ = codes.Put(1, "CN")
+	_ = codes.Put(2, "US")
+	fmt.Println(codes.Put(3, "CN"))
+
+	ids := codes.Inverse()
+	id, _ := ids.Get("US")
+	fmt.Println(id)
+	ids.Delete("CN")
+	_, ok := codes.Get(1)
+	fmt.Println(ok)
+	// Output:
+	// ekit: value 已经和另外一个 key 关联了
+	// 2
+	// false
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"testing"
+
+	"github.com/ecodeclub/ekit/bean/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBiMap_Put(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    []option.Option[BiMap[int, string]]
+		key     int
+		val     string
+		wantErr error
+		wantMap map[int]string
+	}{
+		{
+			name:    "new key",
+			key:     3,
+			val:     "c",
+			wantMap: map[int]string{1: "a", 2: "b", 3: "c"},
+		},
+		{
+			name:    "same pair",
+			key:     1,
+			val:     "a",
+			wantMap: map[int]string{1: "a", 2: "b"},
+		},
+		{
+			name:    "overwrite value",
+			key:     1,
+			val:     "c",
+			wantMap: map[int]string{1: "c", 2: "b"},
+		},
+		{
+			name:    "value conflict",
+			key:     3,
+			val:     "a",
+			wantErr: ErrBiMapValueConflict,
+			wantMap: map[int]string{1: "a", 2: "b"},
+		},
+		{
+			name:    "value conflict with existing key",
+			key:     1,
+			val:     "b",
+			wantErr: ErrBiMapValueConflict,
+			wantMap: map[int]string{1: "a", 2: "b"},
+		},
+		{
+			name:    "force",
+			opts:    []option.Option[BiMap[int, string]]{BiMapWithForcePut[int, string]()},
+			key:     3,
+			val:     "a",
+			wantMap: map[int]string{2: "b", 3: "a"},
+		},
+		{
+			name:    "force with existing key",
+			opts:    []option.Option[BiMap[int, string]]{BiMapWithForcePut[int, string]()},
+			key:     1,
+			val:     "b",
+			wantMap: map[int]string{1: "b"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBiMap[int, string](0, tc.opts...)
+			require.NoError(t, b.Put(1, "a"))
+			require.NoError(t, b.Put(2, "b"))
+			err := b.Put(tc.key, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assertBiMap(t, tc.wantMap, b)
+		})
+	}
+}
+
+func TestBiMap_ForcePut(t *testing.T) {
+	b := NewBiMap[int, string](2)
+	require.NoError(t, b.Put(1, "a"))
+	require.NoError(t, b.Put(2, "b"))
+	b.ForcePut(3, "a")
+	assertBiMap(t, map[int]string{2: "b", 3: "a"}, b)
+	b.ForcePut(2, "a")
+	assertBiMap(t, map[int]string{2: "a"}, b)
+}
+
+func TestBiMap_Delete(t *testing.T) {
+	b := NewBiMap[int, string](0)
+	require.NoError(t, b.Put(1, "a"))
+	require.NoError(t, b.Put(2, "b"))
+	require.NoError(t, b.Put(3, "c"))
+
+	val, ok := b.Delete(1)
+	assert.True(t, ok)
+	assert.Equal(t, "a", val)
+	_, ok = b.Delete(1)
+	assert.False(t, ok)
+
+	key, ok := b.DeleteByValue("b")
+	assert.True(t, ok)
+	assert.Equal(t, 2, key)
+	_, ok = b.DeleteByValue("b")
+	assert.False(t, ok)
+	assertBiMap(t, map[int]string{3: "c"}, b)
+
+	// 删除之后 value 可以和别的 key 关联
+	require.NoError(t, b.Put(4, "a"))
+	assertBiMap(t, map[int]string{3: "c", 4: "a"}, b)
+}
+
+func TestBiMap_Inverse(t *testing.T) {
+	b := NewBiMap[int, string](0)
+	require.NoError(t, b.Put(1, "a"))
+	inv := b.Inverse()
+	assert.Same(t, inv, b.Inverse())
+	assert.Same(t, b, inv.Inverse())
+
+	key, ok := inv.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, key)
+	val, ok := inv.GetByValue(1)
+	assert.True(t, ok)
+	assert.Equal(t, "a", val)
+
+	// 通过视图修改会体现在原本的 BiMap 上
+	require.NoError(t, inv.Put("b", 2))
+	assert.Equal(t, ErrBiMapValueConflict, inv.Put("c", 2))
+	assertBiMap(t, map[int]string{1: "a", 2: "b"}, b)
+	_, ok = inv.Delete("a")
+	assert.True(t, ok)
+	assertBiMap(t, map[int]string{2: "b"}, b)
+
+	// 反过来也一样
+	require.NoError(t, b.Put(3, "c"))
+	assertBiMap(t, map[string]int{"b": 2, "c": 3}, inv)
+
+	// 视图的冲突策略和原本的一样
+	forced := NewBiMap[int, string](0, BiMapWithForcePut[int, string]())
+	require.NoError(t, forced.Put(1, "a"))
+	require.NoError(t, forced.Inverse().Put("b", 1))
+	assertBiMap(t, map[int]string{1: "b"}, forced)
+}
+
+func TestBiMap_Iterate(t *testing.T) {
+	b := NewBiMap[int, string](0)
+	require.NoError(t, b.Put(1, "a"))
+	require.NoError(t, b.Put(2, "b"))
+	require.NoError(t, b.Put(3, "c"))
+	cnt := 0
+	b.Iterate(func(key int, val string) bool {
+		cnt++
+		return cnt < 2
+	})
+	assert.Equal(t, 2, cnt)
+}
+
+func assertBiMap[K comparable, V comparable](t *testing.T, want map[K]V, b *BiMap[K, V]) {
+	assert.Equal(t, want, b.forward)
+	backward := make(map[V]K, len(want))
+	for k, v := range want {
+		backward[v] = k
+	}
+	assert.Equal(t, backward, b.backward)
+	assert.Equal(t, int64(len(want)), b.Len())
+	assert.Equal(t, len(want), len(b.Keys()))
+	assert.Equal(t, len(want), len(b.Values()))
+	for k, v := range want {
+		val, ok := b.Get(k)
+		assert.True(t, ok)
+		assert.Equal(t, v, val)
+		key, ok := b.GetByValue(v)
+		assert.True(t, ok)
+		assert.Equal(t, k, key)
+		assert.True(t, b.ContainsValue(v))
+	}
+}
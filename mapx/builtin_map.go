@@ -14,40 +14,40 @@
 
 package mapx
 
-// builtinMap 是对 map 的二次封装
+// BuiltinMap 是对 map 的二次封装，使得内置的 map 也可以作为 Map 使用
 // 主要用于各种装饰器模式中被装饰的那个
-type builtinMap[K comparable, V any] struct {
+type BuiltinMap[K comparable, V any] struct {
 	data map[K]V
 }
 
-func (b *builtinMap[K, V]) Put(key K, val V) error {
+func (b *BuiltinMap[K, V]) Put(key K, val V) error {
 	b.data[key] = val
 	return nil
 }
 
-func (b *builtinMap[K, V]) Get(key K) (V, bool) {
+func (b *BuiltinMap[K, V]) Get(key K) (V, bool) {
 	val, ok := b.data[key]
 	return val, ok
 }
 
-func (b *builtinMap[K, V]) Delete(k K) (V, bool) {
+func (b *BuiltinMap[K, V]) Delete(k K) (V, bool) {
 	v, ok := b.data[k]
 	delete(b.data, k)
 	return v, ok
 }
 
 // Keys 返回的 key 是随机的。即便对于同一个实例，调用两次，得到的结果都可能不同。
-func (b *builtinMap[K, V]) Keys() []K {
+func (b *BuiltinMap[K, V]) Keys() []K {
 	return Keys[K, V](b.data)
 }
 
-func (b *builtinMap[K, V]) Values() []V {
+func (b *BuiltinMap[K, V]) Values() []V {
 	return Values[K, V](b.data)
 }
 
 // Iterate 按照随机顺序遍历, 并对每个键值对执行cb(k, v)
 // 如果cb的返回值为 true 则继续遍历，否则遍历结束
-func (b *builtinMap[K, V]) Iterate(cb func(key K, val V) bool) {
+func (b *BuiltinMap[K, V]) Iterate(cb func(key K, val V) bool) {
 	for k, v := range b.data {
 		if !cb(k, v) {
 			break
@@ -55,12 +55,24 @@ func (b *builtinMap[K, V]) Iterate(cb func(key K, val V) bool) {
 	}
 }
 
-func newBuiltinMap[K comparable, V any](capacity int) *builtinMap[K, V] {
-	return &builtinMap[K, V]{
+// NewBuiltinMap 创建一个初始容量为 capacity 的 BuiltinMap
+func NewBuiltinMap[K comparable, V any](capacity int) *BuiltinMap[K, V] {
+	return &BuiltinMap[K, V]{
 		data: make(map[K]V, capacity),
 	}
 }
 
-func (b *builtinMap[K, V]) Len() int64 {
+// NewBuiltinMapOf 直接使用 data，而不会执行复制
+// data 为 nil 的时候会创建一个新的 map
+func NewBuiltinMapOf[K comparable, V any](data map[K]V) *BuiltinMap[K, V] {
+	if data == nil {
+		data = make(map[K]V)
+	}
+	return &BuiltinMap[K, V]{
+		data: data,
+	}
+}
+
+func (b *BuiltinMap[K, V]) Len() int64 {
 	return int64(len(b.data))
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuiltinMap_Delete(t *testing.T) {
@@ -127,7 +128,7 @@ func TestBuiltinMap_Put(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			m := newBuiltinMap[string, string](tc.cap)
+			m := NewBuiltinMap[string, string](tc.cap)
 			err := m.Put(tc.key, tc.val)
 			assert.Equal(t, tc.wantErr, err)
 			v, ok := m.data[tc.key]
@@ -276,7 +277,7 @@ func TestBuiltinMap_Iterate(t *testing.T) {
 		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
-			builtinMap := newBuiltinMap[int, int](0)
+			builtinMap := NewBuiltinMap[int, int](0)
 			for i := testCase.inputStart; i <= testCase.inputEnd; i++ {
 				assert.Nil(t, builtinMap.Put(i, i))
 			}
@@ -298,7 +299,7 @@ func TestBuiltinMap_Iterate(t *testing.T) {
 }
 
 func TestBuiltinMap_Iterate_OnlyIterateHalf(t *testing.T) {
-	builtinMap := newBuiltinMap[int, int](0)
+	builtinMap := NewBuiltinMap[int, int](0)
 	n := 100
 	for i := 1; i <= n; i++ {
 		assert.Nil(t, builtinMap.Put(i, i))
@@ -315,6 +316,18 @@ func TestBuiltinMap_Iterate_OnlyIterateHalf(t *testing.T) {
 	sort.Ints(arr)
 }
 
-func builtinMapOf[K comparable, V any](data map[K]V) *builtinMap[K, V] {
-	return &builtinMap[K, V]{data: data}
+func builtinMapOf[K comparable, V any](data map[K]V) *BuiltinMap[K, V] {
+	return &BuiltinMap[K, V]{data: data}
+}
+
+func TestNewBuiltinMapOf(t *testing.T) {
+	data := map[string]int{"a": 1}
+	m := NewBuiltinMapOf[string, int](data)
+	// 没有复制
+	require.NoError(t, m.Put("b", 2))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, data)
+
+	m = NewBuiltinMapOf[string, int](nil)
+	require.NoError(t, m.Put("a", 1))
+	assert.Equal(t, int64(1), m.Len())
 }
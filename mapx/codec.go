@@ -0,0 +1,151 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ecodeclub/ekit/internal/codec"
+)
+
+var errLinkedMapNotInitialized = errors.New("ekit: LinkedMap 没有初始化，请使用 NewLinkedXXXMap 创建之后再解码")
+
+var (
+	_ json.Marshaler   = (*TreeMap[int, any])(nil)
+	_ json.Unmarshaler = (*TreeMap[int, any])(nil)
+	_ json.Marshaler   = (*LinkedMap[int, any])(nil)
+	_ json.Unmarshaler = (*LinkedMap[int, any])(nil)
+)
+
+// mapEntry 是有序 map 编码之后的键值对
+// 编码成数组而不是 JSON 对象，是因为 JSON 对象既没有顺序，键也只能是字符串
+type mapEntry[K any, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// MarshalJSON 按照 key 的顺序编码成 [{"key": k, "value": v}, ...]
+func (treeMap *TreeMap[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(treeMap.entries())
+}
+
+// UnmarshalJSON 解码 MarshalJSON 的结果，原有的键值对会被清空
+// 因为解码需要 Comparator，所以 treeMap 必须是通过 NewTreeMap 创建的，
+// 零值的 TreeMap 会返回错误
+func (treeMap *TreeMap[K, V]) UnmarshalJSON(data []byte) error {
+	if codec.IsJSONNull(data) {
+		return nil
+	}
+	var entries []mapEntry[K, V]
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	return treeMap.reset(entries)
+}
+
+// MarshalBinary 使用 gob 编码，键值对的顺序和 MarshalJSON 一样
+func (treeMap *TreeMap[K, V]) MarshalBinary() ([]byte, error) {
+	return codec.GobEncode(treeMap.entries())
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的结果，要求和 UnmarshalJSON 一样
+func (treeMap *TreeMap[K, V]) UnmarshalBinary(data []byte) error {
+	var entries []mapEntry[K, V]
+	if err := codec.GobDecode(data, &entries); err != nil {
+		return err
+	}
+	return treeMap.reset(entries)
+}
+
+func (treeMap *TreeMap[K, V]) entries() []mapEntry[K, V] {
+	if treeMap.tree == nil {
+		return []mapEntry[K, V]{}
+	}
+	return entriesOf[K, V](treeMap)
+}
+
+func (treeMap *TreeMap[K, V]) reset(entries []mapEntry[K, V]) error {
+	if treeMap.tree == nil {
+		return errTreeMapComparatorIsNull
+	}
+	Clear[K, V](treeMap)
+	return putEntries[K, V](treeMap, entries)
+}
+
+// MarshalJSON 按照插入的顺序编码成 [{"key": k, "value": v}, ...]
+func (l *LinkedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.entries())
+}
+
+// UnmarshalJSON 解码 MarshalJSON 的结果，原有的键值对会被清空，解码之后依旧保持编码时的顺序
+// l 必须是通过 NewLinkedHashMap 等方法创建的，零值的 LinkedMap 会返回错误
+func (l *LinkedMap[K, V]) UnmarshalJSON(data []byte) error {
+	if codec.IsJSONNull(data) {
+		return nil
+	}
+	var entries []mapEntry[K, V]
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	return l.reset(entries)
+}
+
+// MarshalBinary 使用 gob 编码，键值对的顺序和 MarshalJSON 一样
+func (l *LinkedMap[K, V]) MarshalBinary() ([]byte, error) {
+	return codec.GobEncode(l.entries())
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的结果，要求和 UnmarshalJSON 一样
+func (l *LinkedMap[K, V]) UnmarshalBinary(data []byte) error {
+	var entries []mapEntry[K, V]
+	if err := codec.GobDecode(data, &entries); err != nil {
+		return err
+	}
+	return l.reset(entries)
+}
+
+func (l *LinkedMap[K, V]) entries() []mapEntry[K, V] {
+	if l.m == nil {
+		return []mapEntry[K, V]{}
+	}
+	return entriesOf[K, V](l)
+}
+
+func (l *LinkedMap[K, V]) reset(entries []mapEntry[K, V]) error {
+	if l.m == nil {
+		return errLinkedMapNotInitialized
+	}
+	Clear[K, V](l)
+	return putEntries[K, V](l, entries)
+}
+
+func entriesOf[K any, V any](m Map[K, V]) []mapEntry[K, V] {
+	res := make([]mapEntry[K, V], 0, m.Len())
+	m.Iterate(func(key K, val V) bool {
+		res = append(res, mapEntry[K, V]{Key: key, Value: val})
+		return true
+	})
+	return res
+}
+
+func putEntries[K any, V any](m Map[K, V], entries []mapEntry[K, V]) error {
+	for _, entry := range entries {
+		if err := m.Put(entry.Key, entry.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ecodeclub/ekit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type codecUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestTreeMap_JSON(t *testing.T) {
+	m, err := NewTreeMap[int, codecUser](ekit.ComparatorRealNumber[int])
+	require.NoError(t, err)
+	require.NoError(t, m.Put(3, codecUser{Name: "Tom", Age: 18}))
+	require.NoError(t, m.Put(1, codecUser{Name: "Jerry", Age: 20}))
+	require.NoError(t, m.Put(2, codecUser{Name: "Alice", Age: 22}))
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"key":1,"value":{"name":"Jerry","age":20}},`+
+		`{"key":2,"value":{"name":"Alice","age":22}},`+
+		`{"key":3,"value":{"name":"Tom","age":18}}]`, string(data))
+
+	// 降序的 Comparator 在解码之后依旧生效
+	decoded, err := NewTreeMap[int, codecUser](func(src, dst int) int {
+		return dst - src
+	})
+	require.NoError(t, err)
+	require.NoError(t, decoded.Put(100, codecUser{}))
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, []int{3, 2, 1}, decoded.Keys())
+	val, ok := decoded.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, codecUser{Name: "Jerry", Age: 20}, val)
+	_, ok = decoded.Get(100)
+	assert.False(t, ok)
+}
+
+func TestTreeMap_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		m        func() *TreeMap[string, int]
+		data     string
+		wantKeys []string
+		wantErr  error
+	}{
+		{
+			name: "empty",
+			m: func() *TreeMap[string, int] {
+				m, _ := NewTreeMap[string, int](compareString)
+				return m
+			},
+			data:     `[]`,
+			wantKeys: []string{},
+		},
+		{
+			name: "duplicate keys",
+			m: func() *TreeMap[string, int] {
+				m, _ := NewTreeMap[string, int](compareString)
+				return m
+			},
+			data:     `[{"key":"b","value":1},{"key":"a","value":2},{"key":"b","value":3}]`,
+			wantKeys: []string{"a", "b"},
+		},
+		{
+			name: "null",
+			m: func() *TreeMap[string, int] {
+				m, _ := NewTreeMap[string, int](compareString)
+				_ = m.Put("a", 1)
+				return m
+			},
+			data:     `null`,
+			wantKeys: []string{"a"},
+		},
+		{
+			name: "zero value",
+			m: func() *TreeMap[string, int] {
+				return &TreeMap[string, int]{}
+			},
+			data:    `[]`,
+			wantErr: errTreeMapComparatorIsNull,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := tc.m()
+			err := json.Unmarshal([]byte(tc.data), m)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantKeys, m.Keys())
+		})
+	}
+
+	t.Run("invalid json", func(t *testing.T) {
+		m, err := NewTreeMap[string, int](compareString)
+		require.NoError(t, err)
+		var typeErr *json.UnmarshalTypeError
+		assert.ErrorAs(t, json.Unmarshal([]byte(`{"a":1}`), m), &typeErr)
+	})
+}
+
+func TestTreeMap_Binary(t *testing.T) {
+	m, err := NewTreeMap[string, codecUser](compareString)
+	require.NoError(t, err)
+	require.NoError(t, m.Put("tom", codecUser{Name: "Tom", Age: 18}))
+	require.NoError(t, m.Put("jerry", codecUser{Name: "Jerry", Age: 20}))
+	data, err := m.MarshalBinary()
+	require.NoError(t, err)
+
+	decoded, err := NewTreeMap[string, codecUser](compareString)
+	require.NoError(t, err)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, []string{"jerry", "tom"}, decoded.Keys())
+	assert.Equal(t, []codecUser{{Name: "Jerry", Age: 20}, {Name: "Tom", Age: 18}}, decoded.Values())
+
+	err = (&TreeMap[string, codecUser]{}).UnmarshalBinary(data)
+	assert.Equal(t, errTreeMapComparatorIsNull, err)
+	err = decoded.UnmarshalBinary([]byte("invalid"))
+	assert.Error(t, err)
+
+	// 空的 TreeMap
+	empty, err := NewTreeMap[string, codecUser](compareString)
+	require.NoError(t, err)
+	data, err = empty.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, int64(0), decoded.Len())
+}
+
+func TestLinkedMap_JSON(t *testing.T) {
+	m := NewLinkedBuiltinMap[string, int](0)
+	require.NoError(t, m.Put("c", 3))
+	require.NoError(t, m.Put("a", 1))
+	require.NoError(t, m.Put("b", 2))
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"key":"c","value":3},{"key":"a","value":1},{"key":"b","value":2}]`, string(data))
+
+	// 解码之后依旧保持插入的顺序
+	decoded, err := NewLinkedTreeMap[string, int](compareString)
+	require.NoError(t, err)
+	require.NoError(t, decoded.Put("d", 4))
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, []string{"c", "a", "b"}, decoded.Keys())
+	assert.Equal(t, []int{3, 1, 2}, decoded.Values())
+
+	assert.Equal(t, errLinkedMapNotInitialized, json.Unmarshal(data, &LinkedMap[string, int]{}))
+	data, err = json.Marshal(&LinkedMap[string, int]{})
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(data))
+}
+
+func TestLinkedMap_Binary(t *testing.T) {
+	m := NewLinkedBuiltinMap[string, int](0)
+	require.NoError(t, m.Put("c", 3))
+	require.NoError(t, m.Put("a", 1))
+	data, err := m.MarshalBinary()
+	require.NoError(t, err)
+
+	decoded := NewLinkedBuiltinMap[string, int](0)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, []string{"c", "a"}, decoded.Keys())
+	assert.Equal(t, errLinkedMapNotInitialized, (&LinkedMap[string, int]{}).UnmarshalBinary(data))
+}
+
+func TestMapEntry_Struct(t *testing.T) {
+	// 作为结构体的字段的时候，需要先用构造方法创建好
+	type response struct {
+		Scores *TreeMap[string, int] `json:"scores"`
+	}
+	scores, err := NewTreeMap[string, int](compareString)
+	require.NoError(t, err)
+	require.NoError(t, scores.Put("tom", 90))
+	data, err := json.Marshal(response{Scores: scores})
+	require.NoError(t, err)
+	assert.Equal(t, `{"scores":[{"key":"tom","value":90}]}`, string(data))
+
+	decodedScores, err := NewTreeMap[string, int](compareString)
+	require.NoError(t, err)
+	resp := response{Scores: decodedScores}
+	require.NoError(t, json.Unmarshal(data, &resp))
+	val, ok := resp.Scores.Get("tom")
+	assert.True(t, ok)
+	assert.Equal(t, 90, val)
+}
+
+func compareString(src, dst string) int {
+	if src < dst {
+		return -1
+	}
+	if src > dst {
+		return 1
+	}
+	return 0
+}
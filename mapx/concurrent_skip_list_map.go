@@ -0,0 +1,348 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ecodeclub/ekit"
+)
+
+const (
+	// skipListMaxLevel 和 skipListP 保证了跳表可以容纳足够多的元素
+	skipListMaxLevel = 32
+	skipListP        = 0.25
+)
+
+var _ Map[any, any] = (*ConcurrentSkipListMap[any, any])(nil)
+
+// ConcurrentSkipListMap 基于跳表实现的线程安全的有序 Map，类似于 Java 的 ConcurrentSkipListMap
+// 采用的是 lazy skip list 算法：
+// 读操作（Get、Floor、Ceiling 和各种遍历）完全不加锁；
+// 写操作（Put、Delete）只会锁住需要修改的结点的前驱，不同位置的写操作可以并发执行。
+// 遍历是弱一致的：遍历的过程中不会阻塞写操作，但是不一定能看到遍历开始之后的修改
+type ConcurrentSkipListMap[K any, V any] struct {
+	compare ekit.Comparator[K]
+	// head 哨兵结点，不存储数据
+	head *skipListMapNode[K, V]
+	size atomic.Int64
+}
+
+type skipListMapNode[K any, V any] struct {
+	key K
+	val atomic.Pointer[V]
+	// next 每一层的后继结点，nil 代表已经到了末尾
+	next []atomic.Pointer[skipListMapNode[K, V]]
+
+	// mutex 修改 next 和 marked 的时候需要持有
+	mutex sync.Mutex
+	// marked 结点已经被逻辑删除
+	marked atomic.Bool
+	// fullyLinked 结点已经插入到了所有层，在此之前，结点被认为是不存在的
+	fullyLinked atomic.Bool
+}
+
+// NewConcurrentSkipListMap 创建一个 ConcurrentSkipListMap
+// 需注意比较器 compare 不能为 nil
+func NewConcurrentSkipListMap[K any, V any](compare ekit.Comparator[K]) (*ConcurrentSkipListMap[K, V], error) {
+	if compare == nil {
+		return nil, errTreeMapComparatorIsNull
+	}
+	return &ConcurrentSkipListMap[K, V]{
+		compare: compare,
+		head:    newSkipListMapNode[K, V](*new(K), nil, skipListMaxLevel),
+	}, nil
+}
+
+func newSkipListMapNode[K any, V any](key K, val *V, level int) *skipListMapNode[K, V] {
+	node := &skipListMapNode[K, V]{
+		key:  key,
+		next: make([]atomic.Pointer[skipListMapNode[K, V]], level),
+	}
+	node.val.Store(val)
+	return node
+}
+
+// Put 插入键值对，如果 key 已经存在，那么原值会被替换
+// 总是返回 nil
+func (m *ConcurrentSkipListMap[K, V]) Put(key K, val V) error {
+	var preds, succs [skipListMaxLevel]*skipListMapNode[K, V]
+	level := randomSkipListLevel()
+	for {
+		found := m.find(key, &preds, &succs)
+		if found >= 0 {
+			node := succs[found]
+			if node.marked.Load() {
+				// 正在被删除，等删除完成之后重试
+				runtime.Gosched()
+				continue
+			}
+			m.waitFullyLinked(node)
+			node.mutex.Lock()
+			if node.marked.Load() {
+				node.mutex.Unlock()
+				continue
+			}
+			node.val.Store(&val)
+			node.mutex.Unlock()
+			return nil
+		}
+
+		// 从下往上锁住前驱，并且校验前驱和后继都没有变化
+		highestLocked := -1
+		valid := true
+		for i := 0; valid && i < level; i++ {
+			pred, succ := preds[i], succs[i]
+			if i == 0 || pred != preds[i-1] {
+				pred.mutex.Lock()
+				highestLocked = i
+			}
+			valid = !pred.marked.Load() && (succ == nil || !succ.marked.Load()) &&
+				pred.next[i].Load() == succ
+		}
+		if !valid {
+			unlockPreds(&preds, highestLocked)
+			continue
+		}
+		node := newSkipListMapNode[K, V](key, &val, level)
+		for i := 0; i < level; i++ {
+			node.next[i].Store(succs[i])
+		}
+		for i := 0; i < level; i++ {
+			preds[i].next[i].Store(node)
+		}
+		node.fullyLinked.Store(true)
+		unlockPreds(&preds, highestLocked)
+		m.size.Add(1)
+		return nil
+	}
+}
+
+// Get 返回 key 对应的值，key 不存在的时候返回 false
+func (m *ConcurrentSkipListMap[K, V]) Get(key K) (V, bool) {
+	pred := m.head
+	for i := skipListMaxLevel - 1; i >= 0; i-- {
+		curr := pred.next[i].Load()
+		for curr != nil && m.compare(curr.key, key) < 0 {
+			pred = curr
+			curr = pred.next[i].Load()
+		}
+		if curr != nil && m.compare(curr.key, key) == 0 {
+			if curr.present() {
+				return *curr.val.Load(), true
+			}
+			break
+		}
+	}
+	var v V
+	return v, false
+}
+
+// Delete 删除 key，返回被删除的值
+// 第二个返回值代表是否真的删除了
+func (m *ConcurrentSkipListMap[K, V]) Delete(key K) (V, bool) {
+	var preds, succs [skipListMaxLevel]*skipListMapNode[K, V]
+	var victim *skipListMapNode[K, V]
+	var val V
+	for {
+		found := m.find(key, &preds, &succs)
+		if victim == nil {
+			if found < 0 {
+				return val, false
+			}
+			node := succs[found]
+			// 只有完全插入，并且是在最高层找到的结点才能删除
+			if !node.fullyLinked.Load() || len(node.next)-1 != found || node.marked.Load() {
+				return val, false
+			}
+			node.mutex.Lock()
+			if node.marked.Load() {
+				node.mutex.Unlock()
+				return val, false
+			}
+			// 逻辑删除，从这一刻开始结点就被认为是不存在的了
+			node.marked.Store(true)
+			val = *node.val.Load()
+			victim = node
+		}
+
+		// 已经逻辑删除了，接下来物理删除，失败了就重试
+		highestLocked := -1
+		valid := true
+		level := len(victim.next)
+		for i := 0; valid && i < level; i++ {
+			pred := preds[i]
+			if i == 0 || pred != preds[i-1] {
+				pred.mutex.Lock()
+				highestLocked = i
+			}
+			valid = !pred.marked.Load() && pred.next[i].Load() == victim
+		}
+		if !valid {
+			unlockPreds(&preds, highestLocked)
+			continue
+		}
+		for i := level - 1; i >= 0; i-- {
+			preds[i].next[i].Store(victim.next[i].Load())
+		}
+		victim.mutex.Unlock()
+		unlockPreds(&preds, highestLocked)
+		m.size.Add(-1)
+		return val, true
+	}
+}
+
+// Floor 返回小于等于 key 的最大的键值对，不存在的时候返回 false
+func (m *ConcurrentSkipListMap[K, V]) Floor(key K) (K, V, bool) {
+	var preds, succs [skipListMaxLevel]*skipListMapNode[K, V]
+	for {
+		found := m.find(key, &preds, &succs)
+		if found >= 0 && succs[found].present() {
+			node := succs[found]
+			return node.key, *node.val.Load(), true
+		}
+		pred := preds[0]
+		if pred == m.head {
+			var k K
+			var v V
+			return k, v, false
+		}
+		if pred.present() {
+			return pred.key, *pred.val.Load(), true
+		}
+		// 前驱正在插入或者删除，没有办法往回走，只能重试
+		runtime.Gosched()
+	}
+}
+
+// Ceiling 返回大于等于 key 的最小的键值对，不存在的时候返回 false
+func (m *ConcurrentSkipListMap[K, V]) Ceiling(key K) (K, V, bool) {
+	var preds, succs [skipListMaxLevel]*skipListMapNode[K, V]
+	m.find(key, &preds, &succs)
+	for curr := succs[0]; curr != nil; curr = curr.next[0].Load() {
+		if curr.present() {
+			return curr.key, *curr.val.Load(), true
+		}
+	}
+	var k K
+	var v V
+	return k, v, false
+}
+
+// Keys 按照 key 的顺序返回全部的键
+func (m *ConcurrentSkipListMap[K, V]) Keys() []K {
+	res := make([]K, 0, m.Len())
+	m.Iterate(func(key K, val V) bool {
+		res = append(res, key)
+		return true
+	})
+	return res
+}
+
+// Values 按照 key 的顺序返回全部的值
+func (m *ConcurrentSkipListMap[K, V]) Values() []V {
+	res := make([]V, 0, m.Len())
+	m.Iterate(func(key K, val V) bool {
+		res = append(res, val)
+		return true
+	})
+	return res
+}
+
+// Len 返回键值对的数量
+// 在有并发修改的时候，返回的只是一个近似值
+func (m *ConcurrentSkipListMap[K, V]) Len() int64 {
+	return m.size.Load()
+}
+
+// Iterate 按照 key 的顺序遍历并执行 cb，如果 cb 返回值为 false 则结束遍历，否则继续遍历
+func (m *ConcurrentSkipListMap[K, V]) Iterate(cb func(key K, val V) bool) {
+	m.iterate(m.head.next[0].Load(), nil, cb)
+}
+
+// Range 按照 key 的顺序遍历 [from, to) 范围内的键值对，cb 返回 false 的时候结束遍历
+func (m *ConcurrentSkipListMap[K, V]) Range(from K, to K, cb func(key K, val V) bool) {
+	var preds, succs [skipListMaxLevel]*skipListMapNode[K, V]
+	m.find(from, &preds, &succs)
+	m.iterate(succs[0], func(key K) bool {
+		return m.compare(key, to) < 0
+	}, cb)
+}
+
+// iterate 从 start 开始在第 0 层遍历，inRange 返回 false 的时候结束遍历
+func (m *ConcurrentSkipListMap[K, V]) iterate(start *skipListMapNode[K, V],
+	inRange func(key K) bool, cb func(key K, val V) bool) {
+	for curr := start; curr != nil; curr = curr.next[0].Load() {
+		if inRange != nil && !inRange(curr.key) {
+			return
+		}
+		if curr.present() && !cb(curr.key, *curr.val.Load()) {
+			return
+		}
+	}
+}
+
+// find 查找 key，preds 和 succs 是每一层中 key 的前驱和后继（后继的 key 大于等于 key）
+// 返回找到 key 的最高层，没有找到的时候返回 -1
+func (m *ConcurrentSkipListMap[K, V]) find(key K,
+	preds *[skipListMaxLevel]*skipListMapNode[K, V],
+	succs *[skipListMaxLevel]*skipListMapNode[K, V]) int {
+	found := -1
+	pred := m.head
+	for i := skipListMaxLevel - 1; i >= 0; i-- {
+		curr := pred.next[i].Load()
+		for curr != nil && m.compare(curr.key, key) < 0 {
+			pred = curr
+			curr = pred.next[i].Load()
+		}
+		if found < 0 && curr != nil && m.compare(curr.key, key) == 0 {
+			found = i
+		}
+		preds[i] = pred
+		succs[i] = curr
+	}
+	return found
+}
+
+func (m *ConcurrentSkipListMap[K, V]) waitFullyLinked(node *skipListMapNode[K, V]) {
+	for !node.fullyLinked.Load() {
+		runtime.Gosched()
+	}
+}
+
+// present 结点已经完全插入，并且没有被删除
+func (n *skipListMapNode[K, V]) present() bool {
+	return n.fullyLinked.Load() && !n.marked.Load()
+}
+
+// unlockPreds 释放 [0, highestLocked] 层中被锁住的前驱，同一个前驱只会被解锁一次
+func unlockPreds[K any, V any](preds *[skipListMaxLevel]*skipListMapNode[K, V], highestLocked int) {
+	for i := 0; i <= highestLocked; i++ {
+		if i == 0 || preds[i] != preds[i-1] {
+			preds[i].mutex.Unlock()
+		}
+	}
+}
+
+func randomSkipListLevel() int {
+	level := 1
+	for level < skipListMaxLevel && rand.Float64() < skipListP {
+		level++
+	}
+	return level
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx_test
+
+import (
+	"fmt"
+
+	"github.com/ecodeclub/ekit"
+	"github.com/ecodeclub/ekit/mapx"
+)
+
+func ExampleConcurrentSkipListMap_Floor() {
+	m, _ := mapx.NewConcurrentSkipListMap[int, string](ekit.ComparatorRealNumber[int])
+	_ = m.Put(10, "a")
+	_ = m.Put(20, "b")
+	_ = m.Put(30, "c")
+	key, val, _ := m.Floor(25)
+	fmt.Println(key, val)
+	key, val, _ = m.Ceiling(25)
+	fmt.Println(key, val)
+	// Output:
+	// 20 b
+	// 30 c
+}
+
+func ExampleConcurrentSkipListMap_Range() {
+	m, _ := mapx.NewConcurrentSkipListMap[int, string](ekit.ComparatorRealNumber[int])
+	_ = m.Put(30, "c")
+	_ = m.Put(10, "a")
+	_ = m.Put(20, "b")
+	m.Range(10, 30, func(key int, val string) bool {
+		fmt.Println(key, val)
+		return true
+	})
+	// Output:
+	// 10 a
+	// 20 b
+}
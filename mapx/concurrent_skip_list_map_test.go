@@ -0,0 +1,235 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/ecodeclub/ekit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConcurrentSkipListMap(t *testing.T) {
+	_, err := NewConcurrentSkipListMap[int, int](nil)
+	assert.Equal(t, errTreeMapComparatorIsNull, err)
+	m, err := NewConcurrentSkipListMap[int, int](ekit.ComparatorRealNumber[int])
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), m.Len())
+	assert.Equal(t, []int{}, m.Keys())
+}
+
+func TestConcurrentSkipListMap_PutGetDelete(t *testing.T) {
+	m := newConcurrentSkipListMap(t, 5, 1, 3, 2, 4)
+	assert.Equal(t, int64(5), m.Len())
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, m.Keys())
+	assert.Equal(t, []int{10, 20, 30, 40, 50}, m.Values())
+
+	// 覆盖
+	require.NoError(t, m.Put(3, 300))
+	val, ok := m.Get(3)
+	assert.True(t, ok)
+	assert.Equal(t, 300, val)
+	assert.Equal(t, int64(5), m.Len())
+
+	_, ok = m.Get(6)
+	assert.False(t, ok)
+
+	val, ok = m.Delete(3)
+	assert.True(t, ok)
+	assert.Equal(t, 300, val)
+	_, ok = m.Delete(3)
+	assert.False(t, ok)
+	_, ok = m.Get(3)
+	assert.False(t, ok)
+	_, ok = m.Delete(100)
+	assert.False(t, ok)
+	assert.Equal(t, int64(4), m.Len())
+	assert.Equal(t, []int{1, 2, 4, 5}, m.Keys())
+
+	// 删除之后可以再次插入
+	require.NoError(t, m.Put(3, 30))
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, m.Keys())
+}
+
+func TestConcurrentSkipListMap_FloorCeiling(t *testing.T) {
+	m := newConcurrentSkipListMap(t, 1, 3, 5)
+	testCases := []struct {
+		name        string
+		key         int
+		wantFloor   int
+		wantFloorOk bool
+		wantCeil    int
+		wantCeilOk  bool
+	}{
+		{
+			name:       "less than all",
+			key:        0,
+			wantCeil:   1,
+			wantCeilOk: true,
+		},
+		{
+			name:        "equal",
+			key:         3,
+			wantFloor:   3,
+			wantFloorOk: true,
+			wantCeil:    3,
+			wantCeilOk:  true,
+		},
+		{
+			name:        "between",
+			key:         4,
+			wantFloor:   3,
+			wantFloorOk: true,
+			wantCeil:    5,
+			wantCeilOk:  true,
+		},
+		{
+			name:        "greater than all",
+			key:         6,
+			wantFloor:   5,
+			wantFloorOk: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, val, ok := m.Floor(tc.key)
+			assert.Equal(t, tc.wantFloorOk, ok)
+			if ok {
+				assert.Equal(t, tc.wantFloor, key)
+				assert.Equal(t, tc.wantFloor*10, val)
+			}
+			key, val, ok = m.Ceiling(tc.key)
+			assert.Equal(t, tc.wantCeilOk, ok)
+			if ok {
+				assert.Equal(t, tc.wantCeil, key)
+				assert.Equal(t, tc.wantCeil*10, val)
+			}
+		})
+	}
+}
+
+func TestConcurrentSkipListMap_Range(t *testing.T) {
+	m := newConcurrentSkipListMap(t, 1, 2, 3, 4, 5)
+	testCases := []struct {
+		name     string
+		from     int
+		to       int
+		limit    int
+		wantKeys []int
+	}{
+		{
+			name:     "all",
+			from:     0,
+			to:       6,
+			limit:    10,
+			wantKeys: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "half open",
+			from:     2,
+			to:       4,
+			limit:    10,
+			wantKeys: []int{2, 3},
+		},
+		{
+			name:     "empty",
+			from:     4,
+			to:       4,
+			limit:    10,
+			wantKeys: []int{},
+		},
+		{
+			name:     "stop early",
+			from:     1,
+			to:       6,
+			limit:    2,
+			wantKeys: []int{1, 2},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys := make([]int, 0, tc.limit)
+			m.Range(tc.from, tc.to, func(key int, val int) bool {
+				keys = append(keys, key)
+				return len(keys) < tc.limit
+			})
+			assert.Equal(t, tc.wantKeys, keys)
+		})
+	}
+}
+
+func TestConcurrentSkipListMap_Concurrent(t *testing.T) {
+	m, err := NewConcurrentSkipListMap[int, int](ekit.ComparatorRealNumber[int])
+	require.NoError(t, err)
+	const goroutines = 8
+	const n = 1000
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(g)))
+			for i := 0; i < n; i++ {
+				// 每个 goroutine 负责自己的 key，同时还会随机地读写公共的 key
+				key := g*n + i
+				assert.NoError(t, m.Put(key, key))
+				shared := -r.Intn(100) - 1
+				switch r.Intn(3) {
+				case 0:
+					assert.NoError(t, m.Put(shared, shared))
+				case 1:
+					m.Delete(shared)
+				default:
+					m.Floor(shared)
+					m.Ceiling(shared)
+				}
+				if i%2 == 1 {
+					val, ok := m.Delete(key - 1)
+					assert.True(t, ok)
+					assert.Equal(t, key-1, val)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// 公共的 key 都是负数，剩下的都是奇数
+	for _, key := range m.Keys() {
+		if key < 0 {
+			m.Delete(key)
+		}
+	}
+	keys := m.Keys()
+	assert.Equal(t, int64(goroutines*n/2), m.Len())
+	assert.Equal(t, goroutines*n/2, len(keys))
+	for i, key := range keys {
+		assert.Equal(t, 2*i+1, key)
+		val, ok := m.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, key, val)
+	}
+}
+
+func newConcurrentSkipListMap(t *testing.T, keys ...int) *ConcurrentSkipListMap[int, int] {
+	m, err := NewConcurrentSkipListMap[int, int](ekit.ComparatorRealNumber[int])
+	require.NoError(t, err)
+	for _, key := range keys {
+		require.NoError(t, m.Put(key, key*10))
+	}
+	return m
+}
@@ -0,0 +1,284 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ecodeclub/ekit/bean/option"
+	"github.com/ecodeclub/ekit/queue"
+)
+
+var _ Map[int, any] = (*ExpiringMap[int, any])(nil)
+
+// ExpiringMap 键值对会过期的线程安全的 map
+// 过期的键值对会通过两种方式删除：
+//  1. 惰性删除：访问的时候发现已经过期了，就直接删除；
+//  2. 主动删除：后台 goroutine 基于时间轮清理过期的键值对，
+//     所以即便不再访问，过期的键值对也不会一直占用内存。
+//
+// 不论是哪一种方式，每个过期的键值对都只会触发一次过期回调。
+// 过期的精度取决于时间轮的 tick，但是惰性删除保证了不会读到已经过期的键值对。
+// 用完之后需要调用 Close 停止后台 goroutine
+type ExpiringMap[K comparable, V any] struct {
+	mutex    sync.RWMutex
+	m        map[K]*expiringEntry[K, V]
+	tick     time.Duration
+	ttl      time.Duration
+	onExpire func(key K, val V)
+
+	tw      *queue.TimingWheel
+	expired *queue.TimingWheelQueue[*expiringEntry[K, V]]
+	done    chan struct{}
+}
+
+type expiringEntry[K comparable, V any] struct {
+	key K
+	val V
+	// deadline 零值表示永不过期
+	deadline time.Time
+	timer    *queue.Timer
+}
+
+func (e *expiringEntry[K, V]) Delay() time.Duration {
+	return time.Until(e.deadline)
+}
+
+func (e *expiringEntry[K, V]) expired(now time.Time) bool {
+	return !e.deadline.IsZero() && !now.Before(e.deadline)
+}
+
+// NewExpiringMap 创建一个 ExpiringMap 并且启动后台清理的 goroutine
+// 默认情况下，Put 放入的键值对永不过期，时间轮的 tick 是 100ms
+func NewExpiringMap[K comparable, V any](opts ...option.Option[ExpiringMap[K, V]]) (*ExpiringMap[K, V], error) {
+	res := &ExpiringMap[K, V]{
+		m:    make(map[K]*expiringEntry[K, V]),
+		tick: 100 * time.Millisecond,
+		done: make(chan struct{}),
+	}
+	option.Apply(res, opts...)
+	tw, err := queue.NewTimingWheel(res.tick)
+	if err != nil {
+		return nil, err
+	}
+	res.tw = tw
+	res.expired = queue.NewTimingWheelQueue[*expiringEntry[K, V]](tw)
+	go res.janitor()
+	return res, nil
+}
+
+// ExpiringMapWithTick 指定时间轮的 tick，也就是主动删除的精度
+func ExpiringMapWithTick[K comparable, V any](tick time.Duration) option.Option[ExpiringMap[K, V]] {
+	return func(m *ExpiringMap[K, V]) {
+		m.tick = tick
+	}
+}
+
+// ExpiringMapWithDefaultTTL 指定 Put 使用的过期时间，小于等于 0 表示永不过期
+func ExpiringMapWithDefaultTTL[K comparable, V any](ttl time.Duration) option.Option[ExpiringMap[K, V]] {
+	return func(m *ExpiringMap[K, V]) {
+		m.ttl = ttl
+	}
+}
+
+// ExpiringMapWithExpireCallback 指定键值对过期之后的回调
+// 回调是在锁之外执行的，所以在回调里面可以继续访问 ExpiringMap。
+// 主动 Delete 和 Put 覆盖旧值都不会触发回调
+func ExpiringMapWithExpireCallback[K comparable, V any](onExpire func(key K, val V)) option.Option[ExpiringMap[K, V]] {
+	return func(m *ExpiringMap[K, V]) {
+		m.onExpire = onExpire
+	}
+}
+
+// Put 使用默认的过期时间放入键值对，总是返回 nil
+func (m *ExpiringMap[K, V]) Put(key K, val V) error {
+	return m.PutWithTTL(key, val, m.ttl)
+}
+
+// PutWithTTL 放入键值对，并且在 ttl 之后过期，ttl 小于等于 0 表示永不过期
+// 如果 key 已经存在，那么会覆盖旧值并且重新计算过期时间。
+// Close 之后依旧可以放入，但是过期的键值对只会被惰性删除。
+// 总是返回 nil
+func (m *ExpiringMap[K, V]) PutWithTTL(key K, val V, ttl time.Duration) error {
+	entry := &expiringEntry[K, V]{key: key, val: val}
+	if ttl > 0 {
+		entry.deadline = time.Now().Add(ttl)
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if old, ok := m.m[key]; ok {
+		old.stop()
+	}
+	m.m[key] = entry
+	if ttl > 0 {
+		// 只有在关闭之后才会返回 error，这时候依赖惰性删除就可以
+		entry.timer, _ = m.expired.Schedule(context.Background(), entry)
+	}
+	return nil
+}
+
+// Get 返回 key 对应的值，已经过期的键值对会被删除
+func (m *ExpiringMap[K, V]) Get(key K) (V, bool) {
+	m.mutex.RLock()
+	entry, ok := m.m[key]
+	m.mutex.RUnlock()
+	if !ok {
+		var v V
+		return v, false
+	}
+	if entry.expired(time.Now()) {
+		m.expire(entry)
+		var v V
+		return v, false
+	}
+	return entry.val, true
+}
+
+// TTL 返回 key 剩余的存活时间，永不过期的键值对返回 0
+func (m *ExpiringMap[K, V]) TTL(key K) (time.Duration, bool) {
+	m.mutex.RLock()
+	entry, ok := m.m[key]
+	m.mutex.RUnlock()
+	if !ok {
+		return 0, false
+	}
+	if entry.deadline.IsZero() {
+		return 0, true
+	}
+	ttl := time.Until(entry.deadline)
+	if ttl <= 0 {
+		m.expire(entry)
+		return 0, false
+	}
+	return ttl, true
+}
+
+// Delete 删除 key，不会触发过期回调
+// 如果 key 已经过期了，那么返回 false
+func (m *ExpiringMap[K, V]) Delete(key K) (V, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var v V
+	entry, ok := m.m[key]
+	if !ok {
+		return v, false
+	}
+	delete(m.m, key)
+	entry.stop()
+	if entry.expired(time.Now()) {
+		return v, false
+	}
+	return entry.val, true
+}
+
+// Keys 返回所有没有过期的键，顺序是随机的
+func (m *ExpiringMap[K, V]) Keys() []K {
+	res := make([]K, 0)
+	m.Iterate(func(key K, val V) bool {
+		res = append(res, key)
+		return true
+	})
+	return res
+}
+
+// Values 返回所有没有过期的值，顺序是随机的
+func (m *ExpiringMap[K, V]) Values() []V {
+	res := make([]V, 0)
+	m.Iterate(func(key K, val V) bool {
+		res = append(res, val)
+		return true
+	})
+	return res
+}
+
+// Len 返回没有过期的键值对的数量，时间复杂度是 O(n)
+func (m *ExpiringMap[K, V]) Len() int64 {
+	var res int64
+	m.Iterate(func(key K, val V) bool {
+		res++
+		return true
+	})
+	return res
+}
+
+// Iterate 遍历所有没有过期的键值对，顺序是随机的
+// 遍历的过程中会一直持有读锁，所以 cb 里面不能修改 ExpiringMap
+func (m *ExpiringMap[K, V]) Iterate(cb func(key K, val V) bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	now := time.Now()
+	for k, entry := range m.m {
+		if entry.expired(now) {
+			continue
+		}
+		if !cb(k, entry.val) {
+			return
+		}
+	}
+}
+
+// Close 停止后台清理的 goroutine
+// 已经进入清理流程的键值对会被删除并且触发回调，其余的键值对只会被惰性删除。
+// 重复调用 Close 不会有任何效果
+func (m *ExpiringMap[K, V]) Close() error {
+	err := m.tw.Close()
+	if err != nil {
+		return err
+	}
+	err = m.expired.Close()
+	if err != nil {
+		return err
+	}
+	<-m.done
+	return nil
+}
+
+func (m *ExpiringMap[K, V]) janitor() {
+	defer close(m.done)
+	for {
+		entry, err := m.expired.Dequeue(context.Background())
+		if err != nil {
+			// 关闭了
+			return
+		}
+		m.expire(entry)
+	}
+}
+
+// expire 删除已经过期的 entry 并且触发回调
+// 只有 entry 依旧在 map 里面的时候才会删除，所以每个 entry 只会触发一次回调
+func (m *ExpiringMap[K, V]) expire(entry *expiringEntry[K, V]) {
+	m.mutex.Lock()
+	cur, ok := m.m[entry.key]
+	if !ok || cur != entry {
+		m.mutex.Unlock()
+		return
+	}
+	delete(m.m, entry.key)
+	entry.stop()
+	m.mutex.Unlock()
+	if m.onExpire != nil {
+		m.onExpire(entry.key, entry.val)
+	}
+}
+
+// stop 取消定时器，必须在锁范围内调用
+func (e *expiringEntry[K, V]) stop() {
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+}
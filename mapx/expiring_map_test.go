@@ -0,0 +1,236 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/bean/option"
+	"github.com/ecodeclub/ekit/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExpiringMap(t *testing.T) {
+	_, err := NewExpiringMap[string, int](ExpiringMapWithTick[string, int](0))
+	assert.Equal(t, errs.NewErrInvalidIntervalValue(0), err)
+}
+
+func TestExpiringMap_PutWithTTL(t *testing.T) {
+	m := newExpiringMap[string, int](t)
+	require.NoError(t, m.PutWithTTL("a", 1, time.Hour))
+	require.NoError(t, m.PutWithTTL("b", 2, 0))
+	require.NoError(t, m.Put("c", 3))
+
+	val, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	ttl, ok := m.TTL("a")
+	assert.True(t, ok)
+	assert.True(t, ttl > 59*time.Minute && ttl <= time.Hour)
+
+	// 永不过期
+	ttl, ok = m.TTL("b")
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), ttl)
+	ttl, ok = m.TTL("c")
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	_, ok = m.TTL("d")
+	assert.False(t, ok)
+	_, ok = m.Get("d")
+	assert.False(t, ok)
+
+	// 覆盖旧值的时候重新计算过期时间
+	require.NoError(t, m.PutWithTTL("b", 20, time.Minute))
+	val, ok = m.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 20, val)
+	ttl, ok = m.TTL("b")
+	assert.True(t, ok)
+	assert.True(t, ttl > 0 && ttl <= time.Minute)
+	assert.Equal(t, int64(3), m.Len())
+}
+
+func TestExpiringMap_LazyExpire(t *testing.T) {
+	var expired []string
+	// tick 足够大，确保过期的键值对只会被惰性删除
+	m := newExpiringMap(t,
+		ExpiringMapWithTick[string, int](time.Hour),
+		ExpiringMapWithDefaultTTL[string, int](time.Millisecond*10),
+		ExpiringMapWithExpireCallback[string, int](func(key string, val int) {
+			expired = append(expired, key)
+		}))
+	require.NoError(t, m.Put("a", 1))
+	require.NoError(t, m.Put("b", 2))
+	require.NoError(t, m.Put("c", 3))
+	require.NoError(t, m.PutWithTTL("d", 4, 0))
+	time.Sleep(time.Millisecond * 20)
+
+	// 遍历会跳过过期的键值对，但是不会删除它们
+	assert.Equal(t, []string{"d"}, m.Keys())
+	assert.Equal(t, []int{4}, m.Values())
+	assert.Equal(t, int64(1), m.Len())
+	assert.Equal(t, 4, len(m.m))
+
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+	_, ok = m.TTL("b")
+	assert.False(t, ok)
+	// 主动删除不会触发回调
+	_, ok = m.Delete("c")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"a", "b"}, expired)
+	assert.Equal(t, 1, len(m.m))
+
+	// 再次访问不会重复触发回调
+	_, ok = m.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"a", "b"}, expired)
+}
+
+func TestExpiringMap_ActiveExpire(t *testing.T) {
+	var mutex sync.Mutex
+	var expired []string
+	m := newExpiringMap(t,
+		ExpiringMapWithTick[string, int](time.Millisecond),
+		ExpiringMapWithExpireCallback[string, int](func(key string, val int) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			expired = append(expired, key)
+		}))
+	require.NoError(t, m.PutWithTTL("a", 1, time.Millisecond*10))
+	require.NoError(t, m.PutWithTTL("b", 2, time.Millisecond*10))
+	require.NoError(t, m.PutWithTTL("c", 3, time.Millisecond*10))
+	require.NoError(t, m.PutWithTTL("d", 4, time.Hour))
+	// 覆盖旧值之后，旧的过期时间就不再生效了
+	require.NoError(t, m.PutWithTTL("b", 20, time.Hour))
+	// 删除之后不会再过期
+	_, ok := m.Delete("c")
+	assert.True(t, ok)
+
+	assert.Eventually(t, func() bool {
+		m.mutex.RLock()
+		defer m.mutex.RUnlock()
+		return len(m.m) == 2
+	}, time.Second, time.Millisecond*10)
+	mutex.Lock()
+	assert.Equal(t, []string{"a"}, expired)
+	mutex.Unlock()
+	keys := m.Keys()
+	sort.Strings(keys)
+	assert.Equal(t, []string{"b", "d"}, keys)
+}
+
+func TestExpiringMap_Delete(t *testing.T) {
+	m := newExpiringMap[string, int](t)
+	require.NoError(t, m.PutWithTTL("a", 1, time.Hour))
+	val, ok := m.Delete("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	_, ok = m.Delete("a")
+	assert.False(t, ok)
+	_, ok = m.Get("a")
+	assert.False(t, ok)
+	// 定时器已经取消了
+	assert.Equal(t, 0, m.tw.Len())
+}
+
+func TestExpiringMap_Iterate(t *testing.T) {
+	m := newExpiringMap[string, int](t)
+	for i, k := range []string{"a", "b", "c"} {
+		require.NoError(t, m.Put(k, i))
+	}
+	cnt := 0
+	m.Iterate(func(key string, val int) bool {
+		cnt++
+		return cnt < 2
+	})
+	assert.Equal(t, 2, cnt)
+}
+
+func TestExpiringMap_Close(t *testing.T) {
+	m, err := NewExpiringMap[string, int](ExpiringMapWithTick[string, int](time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, m.Close())
+	require.NoError(t, m.Close())
+
+	// 关闭之后依旧可以读写，过期的键值对会被惰性删除
+	require.NoError(t, m.PutWithTTL("a", 1, time.Millisecond*10))
+	val, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	time.Sleep(time.Millisecond * 20)
+	assert.Equal(t, 1, len(m.m))
+	_, ok = m.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, len(m.m))
+}
+
+func TestExpiringMap_Concurrent(t *testing.T) {
+	var mutex sync.Mutex
+	expired := make(map[int]int)
+	m := newExpiringMap(t,
+		ExpiringMapWithTick[int, int](time.Millisecond),
+		ExpiringMapWithDefaultTTL[int, int](time.Millisecond*5),
+		ExpiringMapWithExpireCallback[int, int](func(key int, val int) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			expired[key]++
+		}))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := i*100 + j
+				assert.NoError(t, m.Put(key, j))
+				m.Get(key)
+				if j%10 == 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Eventually(t, func() bool {
+		return m.Len() == 0 && len(m.Keys()) == 0
+	}, time.Second, time.Millisecond*10)
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(expired) == 1000
+	}, time.Second, time.Millisecond*10)
+	// 每个键值对只会触发一次回调
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, cnt := range expired {
+		assert.Equal(t, 1, cnt)
+	}
+}
+
+func newExpiringMap[K comparable, V any](t *testing.T, opts ...option.Option[ExpiringMap[K, V]]) *ExpiringMap[K, V] {
+	m, err := NewExpiringMap[K, V](opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = m.Close()
+	})
+	return m
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"bytes"
+	"hash/maphash"
+
+	"github.com/ecodeclub/ekit/internal/hashx"
+)
+
+// hashableSeed 进程级别的随机种子
+// 所以同一个键在不同进程里面的哈希值是不一样的，不要持久化这些哈希值
+var hashableSeed = maphash.MakeSeed()
+
+var (
+	_ Hashable = StringKey("")
+	_ Hashable = IntKey(0)
+	_ Hashable = Int64Key(0)
+	_ Hashable = Uint64Key(0)
+	_ Hashable = BytesKey(nil)
+	_ Hashable = CompositeKey(nil)
+)
+
+// StringKey 可以作为 HashMap 的键的字符串，哈希值使用 maphash 计算
+type StringKey string
+
+func (s StringKey) Code() uint64 {
+	return maphash.String(hashableSeed, string(s))
+}
+
+func (s StringKey) Equals(key any) bool {
+	other, ok := key.(StringKey)
+	return ok && other == s
+}
+
+// IntKey 可以作为 HashMap 的键的 int
+type IntKey int
+
+func (i IntKey) Code() uint64 {
+	return hashx.Mix64(uint64(i))
+}
+
+func (i IntKey) Equals(key any) bool {
+	other, ok := key.(IntKey)
+	return ok && other == i
+}
+
+// Int64Key 可以作为 HashMap 的键的 int64
+type Int64Key int64
+
+func (i Int64Key) Code() uint64 {
+	return hashx.Mix64(uint64(i))
+}
+
+func (i Int64Key) Equals(key any) bool {
+	other, ok := key.(Int64Key)
+	return ok && other == i
+}
+
+// Uint64Key 可以作为 HashMap 的键的 uint64
+type Uint64Key uint64
+
+func (i Uint64Key) Code() uint64 {
+	return hashx.Mix64(uint64(i))
+}
+
+func (i Uint64Key) Equals(key any) bool {
+	other, ok := key.(Uint64Key)
+	return ok && other == i
+}
+
+// BytesKey 可以作为 HashMap 的键的字节切片，比较的是内容而不是地址
+// 放入 HashMap 之后就不能再修改切片的内容，否则会找不到这个键
+type BytesKey []byte
+
+func (b BytesKey) Code() uint64 {
+	return maphash.Bytes(hashableSeed, b)
+}
+
+func (b BytesKey) Equals(key any) bool {
+	other, ok := key.(BytesKey)
+	return ok && bytes.Equal(other, b)
+}
+
+// CompositeKey 由多个 Hashable 组合而成的键，例如 (租户, 用户 ID)
+// 两个 CompositeKey 相等当且仅当它们的长度一样，并且每一个部分都相等。
+// 部分的顺序是有意义的，(a, b) 和 (b, a) 是不同的键
+type CompositeKey []Hashable
+
+// NewCompositeKey 创建一个 CompositeKey
+func NewCompositeKey(parts ...Hashable) CompositeKey {
+	return parts
+}
+
+func (c CompositeKey) Code() uint64 {
+	res := uint64(len(c))
+	for _, part := range c {
+		// 每一步都重新打散，避免交换顺序之后得到同样的哈希值
+		res = hashx.Mix64(res*31 + part.Code())
+	}
+	return res
+}
+
+func (c CompositeKey) Equals(key any) bool {
+	other, ok := key.(CompositeKey)
+	if !ok || len(other) != len(c) {
+		return false
+	}
+	for i, part := range c {
+		if !part.Equals(other[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashable_Equals(t *testing.T) {
+	testCases := []struct {
+		name  string
+		key   Hashable
+		other any
+		want  bool
+	}{
+		{
+			name:  "string equal",
+			key:   StringKey("hello"),
+			other: StringKey("hello"),
+			want:  true,
+		},
+		{
+			name:  "string not equal",
+			key:   StringKey("hello"),
+			other: StringKey("world"),
+		},
+		{
+			name:  "string different type",
+			key:   StringKey("hello"),
+			other: "hello",
+		},
+		{
+			name:  "int equal",
+			key:   IntKey(1),
+			other: IntKey(1),
+			want:  true,
+		},
+		{
+			name:  "int different type",
+			key:   IntKey(1),
+			other: Int64Key(1),
+		},
+		{
+			name:  "int64 equal",
+			key:   Int64Key(-1),
+			other: Int64Key(-1),
+			want:  true,
+		},
+		{
+			name:  "uint64 equal",
+			key:   Uint64Key(1),
+			other: Uint64Key(1),
+			want:  true,
+		},
+		{
+			name:  "uint64 not equal",
+			key:   Uint64Key(1),
+			other: Uint64Key(2),
+		},
+		{
+			name:  "bytes equal",
+			key:   BytesKey("hello"),
+			other: BytesKey("hello"),
+			want:  true,
+		},
+		{
+			name:  "bytes nil and empty",
+			key:   BytesKey(nil),
+			other: BytesKey{},
+			want:  true,
+		},
+		{
+			name:  "bytes not equal",
+			key:   BytesKey("hello"),
+			other: BytesKey("world"),
+		},
+		{
+			name:  "composite equal",
+			key:   NewCompositeKey(StringKey("tenant"), IntKey(1)),
+			other: NewCompositeKey(StringKey("tenant"), IntKey(1)),
+			want:  true,
+		},
+		{
+			name:  "composite different order",
+			key:   NewCompositeKey(IntKey(1), IntKey(2)),
+			other: NewCompositeKey(IntKey(2), IntKey(1)),
+		},
+		{
+			name:  "composite different length",
+			key:   NewCompositeKey(IntKey(1)),
+			other: NewCompositeKey(IntKey(1), IntKey(1)),
+		},
+		{
+			name:  "composite different type",
+			key:   NewCompositeKey(IntKey(1)),
+			other: IntKey(1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.key.Equals(tc.other))
+			if tc.want {
+				// 相等的键必须有同样的哈希值
+				assert.Equal(t, tc.key.Code(), tc.other.(Hashable).Code())
+			}
+		})
+	}
+}
+
+func TestHashable_Code(t *testing.T) {
+	// 好的哈希函数在连续的键上也不应该有冲突
+	testCases := []struct {
+		name string
+		key  func(i int) Hashable
+	}{
+		{
+			name: "string",
+			key: func(i int) Hashable {
+				return StringKey("key" + strconv.Itoa(i))
+			},
+		},
+		{
+			name: "int",
+			key: func(i int) Hashable {
+				return IntKey(i)
+			},
+		},
+		{
+			name: "int64",
+			key: func(i int) Hashable {
+				return Int64Key(i)
+			},
+		},
+		{
+			name: "uint64",
+			key: func(i int) Hashable {
+				return Uint64Key(i)
+			},
+		},
+		{
+			name: "bytes",
+			key: func(i int) Hashable {
+				return BytesKey{byte(i), byte(i >> 8)}
+			},
+		},
+		{
+			name: "composite",
+			key: func(i int) Hashable {
+				return NewCompositeKey(IntKey(i%10), IntKey(i/10))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewHashMap[Hashable, int](0)
+			for i := 0; i < 500; i++ {
+				require.NoError(t, m.Put(tc.key(i), i))
+			}
+			stats := m.Stats()
+			assert.Equal(t, int64(500), stats.Len)
+			assert.Equal(t, int64(0), stats.Collisions)
+			for i := 0; i < 500; i++ {
+				val, ok := m.Get(tc.key(i))
+				assert.True(t, ok)
+				assert.Equal(t, i, val)
+			}
+		})
+	}
+}
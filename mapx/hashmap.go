@@ -40,6 +40,8 @@ type Hashable interface {
 type HashMap[T Hashable, ValType any] struct {
 	hashmap  map[uint64]*node[T, ValType]
 	nodePool *syncx.Pool[*node[T, ValType]]
+	// length 键值对的数量，哈希冲突的时候一个哈希值会对应多个键值对
+	length int64
 }
 
 func (m *HashMap[T, ValType]) Put(key T, val ValType) error {
@@ -49,6 +51,7 @@ func (m *HashMap[T, ValType]) Put(key T, val ValType) error {
 		hash = key.Code()
 		newNode := m.newNode(key, val)
 		m.hashmap[hash] = newNode
+		m.length++
 		return nil
 	}
 	pre := root
@@ -62,6 +65,7 @@ func (m *HashMap[T, ValType]) Put(key T, val ValType) error {
 	}
 	newNode := m.newNode(key, val)
 	pre.next = newNode
+	m.length++
 	return nil
 }
 
@@ -118,7 +122,7 @@ func NewHashMap[T Hashable, ValType any](size int) *HashMap[T, ValType] {
 	}
 }
 
-var _ mapi[Hashable, any] = (*HashMap[Hashable, any])(nil)
+var _ Map[Hashable, any] = (*HashMap[Hashable, any])(nil)
 
 // Delete 第一个返回值为删除key的值，第二个是hashmap是否真的有这个key
 func (m *HashMap[T, ValType]) Delete(key T) (ValType, bool) {
@@ -139,6 +143,7 @@ func (m *HashMap[T, ValType]) Delete(key T) (ValType, bool) {
 				pre.next = root.next
 			}
 			val := root.value
+			m.length--
 			root.formatting()
 			m.nodePool.Put(root)
 			return val, true
@@ -160,7 +165,7 @@ func (n *node[T, ValType]) formatting() {
 }
 
 func (m *HashMap[T, ValType]) Len() int64 {
-	return int64(len(m.hashmap))
+	return m.length
 }
 
 // Iterate 随机顺序遍历，并对每个键值对执行cb(k, v)
@@ -175,3 +180,43 @@ func (m *HashMap[T, ValType]) Iterate(cb func(key T, val ValType) bool) {
 		}
 	}
 }
+
+// HashMapStats HashMap 的哈希冲突统计信息
+type HashMapStats struct {
+	// Len 键值对的数量
+	Len int64
+	// Buckets 不同的哈希值的数量
+	Buckets int64
+	// Collisions 因为哈希冲突而不得不放进冲突链表的键值对数量，也就是 Len - Buckets
+	Collisions int64
+	// MaxChainLength 最长的冲突链表的长度，没有冲突的时候是 1
+	MaxChainLength int
+}
+
+// CollisionRate 发生冲突的键值对的比例，越接近 0 说明 Code() 的实现越好
+func (s HashMapStats) CollisionRate() float64 {
+	if s.Len == 0 {
+		return 0
+	}
+	return float64(s.Collisions) / float64(s.Len)
+}
+
+// Stats 返回哈希冲突的统计信息，用于发现不好的 Code() 实现
+// 需要遍历所有的键值对，所以时间复杂度是 O(n)
+func (m *HashMap[T, ValType]) Stats() HashMapStats {
+	res := HashMapStats{
+		Buckets: int64(len(m.hashmap)),
+	}
+	for _, root := range m.hashmap {
+		length := 0
+		for cur := root; cur != nil; cur = cur.next {
+			length++
+		}
+		res.Len += int64(length)
+		if length > res.MaxChainLength {
+			res.MaxChainLength = length
+		}
+	}
+	res.Collisions = res.Len - res.Buckets
+	return res
+}
@@ -23,8 +23,8 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-// 借助 testData 来验证一下 HashMap 实现了 mapi 接口
-var _ mapi[testData, int] = &HashMap[testData, int]{}
+// 借助 testData 来验证一下 HashMap 实现了 Map 接口
+var _ Map[testData, int] = &HashMap[testData, int]{}
 
 func TestHashMap(t *testing.T) {
 	testKV := []struct {
@@ -695,3 +695,65 @@ func BenchmarkMyHashMap(b *testing.B) {
 		}
 	})
 }
+
+func TestHashMap_Stats(t *testing.T) {
+	testCases := []struct {
+		name      string
+		ids       []int
+		wantStats HashMapStats
+		wantRate  float64
+	}{
+		{
+			name:      "empty",
+			wantStats: HashMapStats{},
+		},
+		{
+			name: "no collision",
+			ids:  []int{1, 2, 3},
+			wantStats: HashMapStats{
+				Len:            3,
+				Buckets:        3,
+				MaxChainLength: 1,
+			},
+		},
+		{
+			// testData 的哈希值是 id % 10
+			name: "collisions",
+			ids:  []int{1, 11, 21, 2, 12},
+			wantStats: HashMapStats{
+				Len:            5,
+				Buckets:        2,
+				Collisions:     3,
+				MaxChainLength: 3,
+			},
+			wantRate: 0.6,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewHashMap[testData, int](10)
+			for _, id := range tc.ids {
+				require.NoError(t, m.Put(newTestData(id), id))
+			}
+			stats := m.Stats()
+			assert.Equal(t, tc.wantStats, stats)
+			assert.Equal(t, tc.wantRate, stats.CollisionRate())
+			assert.Equal(t, stats.Len, m.Len())
+		})
+	}
+}
+
+func TestHashMap_Len_Collision(t *testing.T) {
+	m := NewHashMap[testData, int](10)
+	for _, id := range []int{1, 11, 21} {
+		require.NoError(t, m.Put(newTestData(id), id))
+	}
+	// 覆盖旧值不会改变长度
+	require.NoError(t, m.Put(newTestData(11), 110))
+	assert.Equal(t, int64(3), m.Len())
+	_, ok := m.Delete(newTestData(11))
+	assert.True(t, ok)
+	_, ok = m.Delete(newTestData(31))
+	assert.False(t, ok)
+	assert.Equal(t, int64(2), m.Len())
+}
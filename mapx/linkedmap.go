@@ -17,7 +17,7 @@ package mapx
 import "github.com/ecodeclub/ekit"
 
 type LinkedMap[K any, V any] struct {
-	m          mapi[K, *linkedKV[K, V]]
+	m          Map[K, *linkedKV[K, V]]
 	head, tail *linkedKV[K, V]
 	length     int
 }
@@ -29,15 +29,12 @@ type linkedKV[K any, V any] struct {
 }
 
 func NewLinkedHashMap[K Hashable, V any](size int) *LinkedMap[K, V] {
-	hashmap := NewHashMap[K, *linkedKV[K, V]](size)
-	head := &linkedKV[K, V]{}
-	tail := &linkedKV[K, V]{next: head, prev: head}
-	head.prev, head.next = tail, tail
-	return &LinkedMap[K, V]{
-		m:    hashmap,
-		head: head,
-		tail: tail,
-	}
+	return newLinkedMap[K, V](NewHashMap[K, *linkedKV[K, V]](size))
+}
+
+// NewLinkedBuiltinMap 创建一个基于内置 map 的 LinkedMap
+func NewLinkedBuiltinMap[K comparable, V any](size int) *LinkedMap[K, V] {
+	return newLinkedMap[K, V](NewBuiltinMap[K, *linkedKV[K, V]](size))
 }
 
 func NewLinkedTreeMap[K any, V any](comparator ekit.Comparator[K]) (*LinkedMap[K, V], error) {
@@ -45,14 +42,18 @@ func NewLinkedTreeMap[K any, V any](comparator ekit.Comparator[K]) (*LinkedMap[K
 	if err != nil {
 		return nil, err
 	}
+	return newLinkedMap[K, V](treeMap), nil
+}
+
+func newLinkedMap[K any, V any](m Map[K, *linkedKV[K, V]]) *LinkedMap[K, V] {
 	head := &linkedKV[K, V]{}
 	tail := &linkedKV[K, V]{next: head, prev: head}
 	head.prev, head.next = tail, tail
 	return &LinkedMap[K, V]{
-		m:    treeMap,
+		m:    m,
 		head: head,
 		tail: tail,
-	}, nil
+	}
 }
 
 func (l *LinkedMap[K, V]) Put(key K, val V) error {
@@ -124,3 +125,14 @@ func (l *LinkedMap[K, V]) Iterate(cb func(K, V) bool) {
 		}
 	}
 }
+
+// moveToBack 将 lk 移动到链表的末尾
+func (l *LinkedMap[K, V]) moveToBack(lk *linkedKV[K, V]) {
+	if lk.next == l.tail {
+		return
+	}
+	lk.prev.next = lk.next
+	lk.next.prev = lk.prev
+	lk.prev, lk.next = l.tail.prev, l.tail
+	lk.prev.next, lk.next.prev = lk, lk
+}
@@ -0,0 +1,233 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"sync"
+
+	"github.com/ecodeclub/ekit/bean/option"
+)
+
+var _ Map[int, any] = (*LRU[int, any])(nil)
+
+// LRU 基于 LinkedMap 实现的 LRU 缓存
+// 链表的头部是最久没有被访问的键值对，末尾是最近被访问的键值对，
+// Put 和 Get 都会把键值对移动到末尾，而 Peek 不会。
+// 默认情况下每个键值对的权重都是 1，也就是说 capacity 是键值对的最大数量；
+// 通过 LRUWithWeigher 可以按照键值对的大小来计算权重，此时 capacity 是权重之和的上限。
+// 总权重超过 capacity 的时候，会从头部开始淘汰键值对。
+// LRU 不是线程安全的，并发场景下使用 ConcurrentLRU
+type LRU[K comparable, V any] struct {
+	linked   *LinkedMap[K, V]
+	capacity int64
+	// weight 当前所有键值对的权重之和
+	weight  int64
+	weigher func(key K, val V) int64
+	onEvict func(key K, val V)
+}
+
+// NewLRU 创建一个 LRU 缓存，capacity 小于等于 0 的时候不会淘汰任何键值对
+func NewLRU[K comparable, V any](capacity int64, opts ...option.Option[LRU[K, V]]) *LRU[K, V] {
+	res := &LRU[K, V]{
+		linked:   NewLinkedBuiltinMap[K, V](0),
+		capacity: capacity,
+		weigher: func(key K, val V) int64 {
+			return 1
+		},
+	}
+	option.Apply(res, opts...)
+	return res
+}
+
+// LRUWithWeigher 指定键值对的权重，weigher 必须是确定的，也就是同样的键值对总是返回同样的权重
+func LRUWithWeigher[K comparable, V any](weigher func(key K, val V) int64) option.Option[LRU[K, V]] {
+	return func(l *LRU[K, V]) {
+		l.weigher = weigher
+	}
+}
+
+// LRUWithEvictCallback 指定淘汰键值对之后的回调
+// 只有因为超过容量而被淘汰的时候才会调用，主动 Delete 和 Put 覆盖旧值都不会调用
+func LRUWithEvictCallback[K comparable, V any](onEvict func(key K, val V)) option.Option[LRU[K, V]] {
+	return func(l *LRU[K, V]) {
+		l.onEvict = onEvict
+	}
+}
+
+// Put 放入键值对，并且将其标记为最近访问过的
+// 超过容量的时候会淘汰最久没有被访问的键值对。
+// 如果单个键值对的权重就已经超过了容量，那么它自己也会被淘汰。
+// 总是返回 nil
+func (l *LRU[K, V]) Put(key K, val V) error {
+	evicted := l.put(key, val)
+	l.evicted(evicted)
+	return nil
+}
+
+// Get 返回 key 对应的值，并且将其标记为最近访问过的
+func (l *LRU[K, V]) Get(key K) (V, bool) {
+	lk, ok := l.linked.m.Get(key)
+	if !ok {
+		var v V
+		return v, false
+	}
+	l.linked.moveToBack(lk)
+	return lk.value, true
+}
+
+// Peek 返回 key 对应的值，但是不会影响淘汰的顺序
+func (l *LRU[K, V]) Peek(key K) (V, bool) {
+	return l.linked.Get(key)
+}
+
+// Delete 删除 key，不会触发淘汰回调
+func (l *LRU[K, V]) Delete(key K) (V, bool) {
+	val, ok := l.linked.Delete(key)
+	if ok {
+		l.weight -= l.weigher(key, val)
+	}
+	return val, ok
+}
+
+// Keys 按照从最久没有被访问到最近被访问的顺序返回所有的键
+func (l *LRU[K, V]) Keys() []K {
+	return l.linked.Keys()
+}
+
+// Values 按照从最久没有被访问到最近被访问的顺序返回所有的值
+func (l *LRU[K, V]) Values() []V {
+	return l.linked.Values()
+}
+
+func (l *LRU[K, V]) Len() int64 {
+	return l.linked.Len()
+}
+
+// Weight 返回当前所有键值对的权重之和
+func (l *LRU[K, V]) Weight() int64 {
+	return l.weight
+}
+
+// Iterate 按照从最久没有被访问到最近被访问的顺序遍历，不会影响淘汰的顺序
+func (l *LRU[K, V]) Iterate(cb func(key K, val V) bool) {
+	l.linked.Iterate(cb)
+}
+
+// put 放入键值对，返回被淘汰的键值对
+func (l *LRU[K, V]) put(key K, val V) []*linkedKV[K, V] {
+	if lk, ok := l.linked.m.Get(key); ok {
+		l.weight += l.weigher(key, val) - l.weigher(key, lk.value)
+		lk.value = val
+		l.linked.moveToBack(lk)
+	} else {
+		// 内置 map 的 Put 不会返回 error
+		_ = l.linked.Put(key, val)
+		l.weight += l.weigher(key, val)
+	}
+	if l.capacity <= 0 {
+		return nil
+	}
+	var evicted []*linkedKV[K, V]
+	for l.weight > l.capacity && l.linked.length > 0 {
+		oldest := l.linked.head.next
+		l.linked.Delete(oldest.key)
+		l.weight -= l.weigher(oldest.key, oldest.value)
+		evicted = append(evicted, oldest)
+	}
+	return evicted
+}
+
+func (l *LRU[K, V]) evicted(evicted []*linkedKV[K, V]) {
+	if l.onEvict == nil {
+		return
+	}
+	for _, lk := range evicted {
+		l.onEvict(lk.key, lk.value)
+	}
+}
+
+var _ Map[int, any] = (*ConcurrentLRU[int, any])(nil)
+
+// ConcurrentLRU 线程安全的 LRU 缓存
+// 因为 Get 也会修改淘汰的顺序，所以所有的操作都需要加锁。
+// 淘汰回调是在锁之外执行的，所以在回调里面可以继续访问缓存
+type ConcurrentLRU[K comparable, V any] struct {
+	mutex sync.Mutex
+	lru   *LRU[K, V]
+}
+
+// NewConcurrentLRU 创建一个线程安全的 LRU 缓存，参数的含义和 NewLRU 一样
+func NewConcurrentLRU[K comparable, V any](capacity int64, opts ...option.Option[LRU[K, V]]) *ConcurrentLRU[K, V] {
+	return &ConcurrentLRU[K, V]{
+		lru: NewLRU[K, V](capacity, opts...),
+	}
+}
+
+func (c *ConcurrentLRU[K, V]) Put(key K, val V) error {
+	c.mutex.Lock()
+	evicted := c.lru.put(key, val)
+	c.mutex.Unlock()
+	c.lru.evicted(evicted)
+	return nil
+}
+
+func (c *ConcurrentLRU[K, V]) Get(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Get(key)
+}
+
+func (c *ConcurrentLRU[K, V]) Peek(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Peek(key)
+}
+
+func (c *ConcurrentLRU[K, V]) Delete(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Delete(key)
+}
+
+func (c *ConcurrentLRU[K, V]) Keys() []K {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Keys()
+}
+
+func (c *ConcurrentLRU[K, V]) Values() []V {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Values()
+}
+
+func (c *ConcurrentLRU[K, V]) Len() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
+
+func (c *ConcurrentLRU[K, V]) Weight() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Weight()
+}
+
+// Iterate 遍历的过程中会一直持有锁，所以 cb 里面不能再访问缓存
+func (c *ConcurrentLRU[K, V]) Iterate(cb func(key K, val V) bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lru.Iterate(cb)
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx_test
+
+import (
+	"fmt"
+
+	"github.com/ecodeclub/ekit/mapx"
+)
+
+func ExampleLRU() {
+	lru := mapx.NewLRU[string, int](2, mapx.LRUWithEvictCallback[string, int](func(key string, val int) {
+		fmt.Println("evict", key)
+	}))
+	_ = lru.Put("a", 1)
+	_ = lru.Put("b", 2)
+	lru.Get("a")
+	_ = lru.Put("c", 3)
+	fmt.Println(lru.Keys())
+	// Output:
+	// evict b
+	// [a c]
+}
@@ -0,0 +1,223 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLinkedBuiltinMap(t *testing.T) {
+	m := NewLinkedBuiltinMap[string, int](0)
+	require.NoError(t, m.Put("b", 2))
+	require.NoError(t, m.Put("a", 1))
+	require.NoError(t, m.Put("c", 3))
+	assert.Equal(t, []string{"b", "a", "c"}, m.Keys())
+	assert.Equal(t, []int{2, 1, 3}, m.Values())
+}
+
+func TestLRU_Put(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int64
+		keys     []int
+		wantKeys []int
+	}{
+		{
+			name:     "not full",
+			capacity: 3,
+			keys:     []int{1, 2},
+			wantKeys: []int{1, 2},
+		},
+		{
+			name:     "evict oldest",
+			capacity: 3,
+			keys:     []int{1, 2, 3, 4, 5},
+			wantKeys: []int{3, 4, 5},
+		},
+		{
+			name:     "put existing key",
+			capacity: 3,
+			keys:     []int{1, 2, 3, 1, 4},
+			wantKeys: []int{3, 1, 4},
+		},
+		{
+			name:     "unbounded",
+			capacity: 0,
+			keys:     []int{1, 2, 3, 4, 5},
+			wantKeys: []int{1, 2, 3, 4, 5},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lru := NewLRU[int, int](tc.capacity)
+			for _, k := range tc.keys {
+				require.NoError(t, lru.Put(k, k*10))
+			}
+			assert.Equal(t, tc.wantKeys, lru.Keys())
+			wantVals := make([]int, 0, len(tc.wantKeys))
+			for _, k := range tc.wantKeys {
+				wantVals = append(wantVals, k*10)
+			}
+			assert.Equal(t, wantVals, lru.Values())
+			assert.Equal(t, int64(len(tc.wantKeys)), lru.Len())
+			assert.Equal(t, int64(len(tc.wantKeys)), lru.Weight())
+		})
+	}
+}
+
+func TestLRU_GetAndPeek(t *testing.T) {
+	lru := NewLRU[int, int](3)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, lru.Put(i, i))
+	}
+
+	// Peek 不会影响淘汰的顺序
+	val, ok := lru.Peek(1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, []int{1, 2, 3}, lru.Keys())
+
+	// Get 会将 1 标记为最近访问过的，所以淘汰的是 2
+	val, ok = lru.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, []int{2, 3, 1}, lru.Keys())
+	require.NoError(t, lru.Put(4, 4))
+	assert.Equal(t, []int{3, 1, 4}, lru.Keys())
+
+	_, ok = lru.Get(2)
+	assert.False(t, ok)
+	_, ok = lru.Peek(2)
+	assert.False(t, ok)
+}
+
+func TestLRU_Delete(t *testing.T) {
+	evicted := 0
+	lru := NewLRU[int, int](3, LRUWithEvictCallback[int, int](func(key int, val int) {
+		evicted++
+	}))
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, lru.Put(i, i))
+	}
+	val, ok := lru.Delete(2)
+	assert.True(t, ok)
+	assert.Equal(t, 2, val)
+	_, ok = lru.Delete(2)
+	assert.False(t, ok)
+	assert.Equal(t, []int{1, 3}, lru.Keys())
+	assert.Equal(t, int64(2), lru.Weight())
+
+	// 删除之后有空位，不会淘汰
+	require.NoError(t, lru.Put(4, 4))
+	assert.Equal(t, []int{1, 3, 4}, lru.Keys())
+	// 主动删除不会触发回调
+	assert.Equal(t, 0, evicted)
+}
+
+func TestLRU_Weigher(t *testing.T) {
+	var evictedKeys []string
+	lru := NewLRU[string, string](10,
+		LRUWithWeigher[string, string](func(key string, val string) int64 {
+			return int64(len(val))
+		}),
+		LRUWithEvictCallback[string, string](func(key string, val string) {
+			evictedKeys = append(evictedKeys, key)
+		}))
+	require.NoError(t, lru.Put("a", "aaaa"))
+	require.NoError(t, lru.Put("b", "bbb"))
+	require.NoError(t, lru.Put("c", "cc"))
+	assert.Equal(t, int64(9), lru.Weight())
+	assert.Nil(t, evictedKeys)
+
+	// 一次淘汰多个键值对
+	require.NoError(t, lru.Put("d", "dddddddd"))
+	assert.Equal(t, []string{"a", "b"}, evictedKeys)
+	assert.Equal(t, []string{"c", "d"}, lru.Keys())
+	assert.Equal(t, int64(10), lru.Weight())
+
+	// 覆盖旧值的时候重新计算权重
+	require.NoError(t, lru.Put("c", "c"))
+	assert.Equal(t, int64(9), lru.Weight())
+	assert.Equal(t, []string{"d", "c"}, lru.Keys())
+
+	// 超过容量的键值对自己也会被淘汰
+	evictedKeys = nil
+	require.NoError(t, lru.Put("e", "eeeeeeeeeee"))
+	assert.Equal(t, []string{"d", "c", "e"}, evictedKeys)
+	assert.Equal(t, int64(0), lru.Len())
+	assert.Equal(t, int64(0), lru.Weight())
+}
+
+func TestLRU_Iterate(t *testing.T) {
+	lru := NewLRU[int, int](3)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, lru.Put(i, i))
+	}
+	var keys []int
+	lru.Iterate(func(key int, val int) bool {
+		keys = append(keys, key)
+		return key < 2
+	})
+	assert.Equal(t, []int{1, 2}, keys)
+	// 遍历不会影响淘汰的顺序
+	assert.Equal(t, []int{1, 2, 3}, lru.Keys())
+}
+
+func TestConcurrentLRU(t *testing.T) {
+	var mutex sync.Mutex
+	evicted := 0
+	var lru *ConcurrentLRU[string, int]
+	lru = NewConcurrentLRU[string, int](100, LRUWithEvictCallback[string, int](func(key string, val int) {
+		// 回调在锁之外执行，所以可以访问缓存
+		_, ok := lru.Peek(key)
+		assert.False(t, ok)
+		mutex.Lock()
+		evicted++
+		mutex.Unlock()
+	}))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := strconv.Itoa(i*100 + j)
+				assert.NoError(t, lru.Put(key, j))
+				lru.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(100), lru.Len())
+	assert.Equal(t, int64(100), lru.Weight())
+	assert.Equal(t, 900, evicted)
+	assert.Equal(t, 100, len(lru.Keys()))
+	assert.Equal(t, 100, len(lru.Values()))
+
+	key := lru.Keys()[0]
+	_, ok := lru.Delete(key)
+	assert.True(t, ok)
+	cnt := 0
+	lru.Iterate(func(key string, val int) bool {
+		cnt++
+		return true
+	})
+	assert.Equal(t, 99, cnt)
+}
@@ -14,7 +14,12 @@
 
 package mapx
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ecodeclub/ekit"
+)
 
 // Keys 返回 map 里面的所有的 key。
 // 需要注意：这些 key 的顺序是随机。
@@ -100,3 +105,141 @@ func MergeFunc[K comparable, V any](mergeFunc func(val1, val2 V) V, ms ...map[K]
 	}
 	return merged
 }
+
+// Filter 返回一个新的 map，只包含 predicate 返回 true 的键值对
+func Filter[K comparable, V any](m map[K]V, predicate func(key K, val V) bool) map[K]V {
+	res := make(map[K]V)
+	for k, v := range m {
+		if predicate(k, v) {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// MapValues 返回一个新的 map，key 不变，value 是 fn 的返回值
+func MapValues[K comparable, V any, R any](m map[K]V, fn func(key K, val V) R) map[K]R {
+	res := make(map[K]R, len(m))
+	for k, v := range m {
+		res[k] = fn(k, v)
+	}
+	return res
+}
+
+// MapKeys 返回一个新的 map，value 不变，key 是 fn 的返回值
+// 如果多个 key 转换之后是同一个 key，那么使用 mergeFunc 合并它们的 value；
+// mergeFunc 为 nil 的时候保留最后遍历到的 value，因为遍历顺序是随机的，所以具体是哪一个是不确定的。
+// 注意：map 的遍历顺序是随机的，所以 mergeFunc 的结果不应该依赖于参数的顺序
+func MapKeys[K comparable, V any, R comparable](m map[K]V, fn func(key K, val V) R,
+	mergeFunc func(val1, val2 V) V) map[R]V {
+	res := make(map[R]V, len(m))
+	for k, v := range m {
+		key := fn(k, v)
+		if value, ok := res[key]; ok && mergeFunc != nil {
+			res[key] = mergeFunc(value, v)
+		} else {
+			res[key] = v
+		}
+	}
+	return res
+}
+
+// Invert 返回一个 key 和 value 互换的新 map
+// 如果有多个 key 对应同一个 value，那么会返回 error
+func Invert[K comparable, V comparable](m map[K]V) (map[V]K, error) {
+	res := make(map[V]K, len(m))
+	for k, v := range m {
+		if _, ok := res[v]; ok {
+			return nil, fmt.Errorf("ekit: 多个 key 对应了同一个 value %v", v)
+		}
+		res[v] = k
+	}
+	return res, nil
+}
+
+// GroupBy 按照 keyFunc 的返回值对 ts 分组
+// 每一组里面的元素保持它们在 ts 中的顺序
+func GroupBy[T any, K comparable](ts []T, keyFunc func(t T) K) map[K][]T {
+	res := make(map[K][]T)
+	for _, t := range ts {
+		key := keyFunc(t)
+		res[key] = append(res[key], t)
+	}
+	return res
+}
+
+// ValueChange 某个 key 的 value 的变化
+type ValueChange[V any] struct {
+	Old V
+	New V
+}
+
+// MapDiff 两个 map 之间的差异
+type MapDiff[K comparable, V any] struct {
+	// Added 只在新 map 里面的键值对
+	Added map[K]V
+	// Removed 只在旧 map 里面的键值对
+	Removed map[K]V
+	// Changed 两个 map 都有，但是 value 不同的键值对
+	Changed map[K]ValueChange[V]
+}
+
+// Diff 比较 src 和 dst，返回从 src 变成 dst 需要的变化
+// equal 用于判断两个 value 是否相同
+func Diff[K comparable, V any](src, dst map[K]V, equal func(src, dst V) bool) MapDiff[K, V] {
+	res := MapDiff[K, V]{
+		Added:   make(map[K]V),
+		Removed: make(map[K]V),
+		Changed: make(map[K]ValueChange[V]),
+	}
+	for k, v := range src {
+		newVal, ok := dst[k]
+		if !ok {
+			res.Removed[k] = v
+			continue
+		}
+		if !equal(v, newVal) {
+			res.Changed[k] = ValueChange[V]{Old: v, New: newVal}
+		}
+	}
+	for k, v := range dst {
+		if _, ok := src[k]; !ok {
+			res.Added[k] = v
+		}
+	}
+	return res
+}
+
+// Equal 判断两个 map 是否相同，也就是 key 的集合相同，并且每个 key 的 value 都满足 equal
+// nil map 和空的 map 被认为是相同的
+func Equal[K comparable, V any](m1, m2 map[K]V, equal func(src, dst V) bool) bool {
+	if len(m1) != len(m2) {
+		return false
+	}
+	for k, v1 := range m1 {
+		v2, ok := m2[k]
+		if !ok || !equal(v1, v2) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortedKeys 返回按照 comparator 从小到大排序的 key
+func SortedKeys[K comparable, V any](m map[K]V, comparator ekit.Comparator[K]) []K {
+	keys := Keys[K, V](m)
+	sort.Slice(keys, func(i, j int) bool {
+		return comparator(keys[i], keys[j]) < 0
+	})
+	return keys
+}
+
+// IterateSorted 按照 key 从小到大的顺序遍历，如果 cb 返回 false 则结束遍历
+// 需要先对所有的 key 排序，所以时间复杂度是 O(nlogn)
+func IterateSorted[K comparable, V any](m map[K]V, comparator ekit.Comparator[K], cb func(key K, val V) bool) {
+	for _, k := range SortedKeys[K, V](m, comparator) {
+		if !cb(k, m[k]) {
+			return
+		}
+	}
+}
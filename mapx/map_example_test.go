@@ -95,3 +95,19 @@ func ExampleMergeFunc() {
 	// Output:
 	// map[1:3 2:5 3:7]
 }
+
+func ExampleDiff() {
+	before := map[string]int{"tom": 18, "jerry": 20, "alice": 22}
+	after := map[string]int{"tom": 19, "jerry": 20, "bob": 30}
+	diff := mapx.Diff(before, after, func(src, dst int) bool {
+		return src == dst
+	})
+	fmt.Println(diff.Added)
+	fmt.Println(diff.Removed)
+	fmt.Println(diff.Changed)
+
+	// Output:
+	// map[bob:30]
+	// map[alice:22]
+	// map[tom:{18 19}]
+}
@@ -16,9 +16,12 @@ package mapx
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/ecodeclub/ekit"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestKeys(t *testing.T) {
@@ -259,3 +262,239 @@ func TestMergeFunc(t *testing.T) {
 	}, input2...)
 	assert.Equal(t, want2, got)
 }
+
+func TestFilter(t *testing.T) {
+	testCases := []struct {
+		name string
+		m    map[string]int
+		want map[string]int
+	}{
+		{
+			name: "nil",
+			want: map[string]int{},
+		},
+		{
+			name: "filter",
+			m:    map[string]int{"a": 1, "b": 2, "c": 3, "d": 4},
+			want: map[string]int{"b": 2, "d": 4},
+		},
+		{
+			name: "none",
+			m:    map[string]int{"a": 1, "c": 3},
+			want: map[string]int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Filter(tc.m, func(key string, val int) bool {
+				return val%2 == 0
+			})
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMapValues(t *testing.T) {
+	got := MapValues(map[string]int{"a": 1, "b": 2}, func(key string, val int) string {
+		return fmt.Sprintf("%s=%d", key, val)
+	})
+	assert.Equal(t, map[string]string{"a": "a=1", "b": "b=2"}, got)
+	assert.Equal(t, map[string]string{}, MapValues(map[string]int(nil), func(key string, val int) string {
+		return key
+	}))
+}
+
+func TestMapKeys(t *testing.T) {
+	testCases := []struct {
+		name string
+		m    map[string]int
+		want map[string]int
+	}{
+		{
+			name: "nil",
+			want: map[string]int{},
+		},
+		{
+			name: "no collision",
+			m:    map[string]int{"a": 1, "b": 2},
+			want: map[string]int{"A": 1, "B": 2},
+		},
+		{
+			name: "collision",
+			m:    map[string]int{"a": 1, "A": 2, "b": 3},
+			want: map[string]int{"A": 3, "B": 3},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MapKeys(tc.m, func(key string, val int) string {
+				return strings.ToUpper(key)
+			}, func(val1, val2 int) int {
+				return val1 + val2
+			})
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("nil merge func", func(t *testing.T) {
+		got := MapKeys(map[string]int{"a": 1, "A": 2, "b": 3}, func(key string, val int) string {
+			return strings.ToUpper(key)
+		}, nil)
+		assert.Equal(t, 2, len(got))
+		assert.Contains(t, []int{1, 2}, got["A"])
+		assert.Equal(t, 3, got["B"])
+	})
+}
+
+func TestInvert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		m       map[string]int
+		want    map[int]string
+		wantErr error
+	}{
+		{
+			name: "nil",
+			want: map[int]string{},
+		},
+		{
+			name: "invert",
+			m:    map[string]int{"a": 1, "b": 2},
+			want: map[int]string{1: "a", 2: "b"},
+		},
+		{
+			name:    "duplicate value",
+			m:       map[string]int{"a": 1, "b": 1},
+			wantErr: fmt.Errorf("ekit: 多个 key 对应了同一个 value %v", 1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Invert(tc.m)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	got := GroupBy([]string{"apple", "bob", "avocado", "banana", "cat"}, func(t string) byte {
+		return t[0]
+	})
+	assert.Equal(t, map[byte][]string{
+		'a': {"apple", "avocado"},
+		'b': {"bob", "banana"},
+		'c': {"cat"},
+	}, got)
+	assert.Equal(t, map[byte][]string{}, GroupBy([]string(nil), func(t string) byte {
+		return t[0]
+	}))
+}
+
+func TestDiff(t *testing.T) {
+	equal := func(src, dst int) bool {
+		return src == dst
+	}
+	testCases := []struct {
+		name string
+		src  map[string]int
+		dst  map[string]int
+		want MapDiff[string, int]
+	}{
+		{
+			name: "nil",
+			want: MapDiff[string, int]{
+				Added:   map[string]int{},
+				Removed: map[string]int{},
+				Changed: map[string]ValueChange[int]{},
+			},
+		},
+		{
+			name: "same",
+			src:  map[string]int{"a": 1},
+			dst:  map[string]int{"a": 1},
+			want: MapDiff[string, int]{
+				Added:   map[string]int{},
+				Removed: map[string]int{},
+				Changed: map[string]ValueChange[int]{},
+			},
+		},
+		{
+			name: "all changes",
+			src:  map[string]int{"a": 1, "b": 2, "c": 3},
+			dst:  map[string]int{"b": 2, "c": 4, "d": 5},
+			want: MapDiff[string, int]{
+				Added:   map[string]int{"d": 5},
+				Removed: map[string]int{"a": 1},
+				Changed: map[string]ValueChange[int]{"c": {Old: 3, New: 4}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Diff(tc.src, tc.dst, equal))
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	equal := func(src, dst []int) bool {
+		return fmt.Sprint(src) == fmt.Sprint(dst)
+	}
+	testCases := []struct {
+		name string
+		m1   map[string][]int
+		m2   map[string][]int
+		want bool
+	}{
+		{
+			name: "nil and empty",
+			m2:   map[string][]int{},
+			want: true,
+		},
+		{
+			name: "equal",
+			m1:   map[string][]int{"a": {1, 2}, "b": nil},
+			m2:   map[string][]int{"a": {1, 2}, "b": nil},
+			want: true,
+		},
+		{
+			name: "different length",
+			m1:   map[string][]int{"a": {1, 2}},
+			m2:   map[string][]int{"a": {1, 2}, "b": nil},
+		},
+		{
+			name: "different key",
+			m1:   map[string][]int{"a": {1, 2}},
+			m2:   map[string][]int{"b": {1, 2}},
+		},
+		{
+			name: "different value",
+			m1:   map[string][]int{"a": {1, 2}},
+			m2:   map[string][]int{"a": {2, 1}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Equal(tc.m1, tc.m2, equal))
+		})
+	}
+}
+
+func TestSortedKeys(t *testing.T) {
+	m := map[int]string{3: "c", 1: "a", 2: "b"}
+	assert.Equal(t, []int{1, 2, 3}, SortedKeys(m, ekit.ComparatorRealNumber[int]))
+	assert.Equal(t, []int{}, SortedKeys(map[int]string(nil), ekit.ComparatorRealNumber[int]))
+
+	var keys []int
+	var vals []string
+	IterateSorted(m, func(src, dst int) int {
+		return dst - src
+	}, func(key int, val string) bool {
+		keys = append(keys, key)
+		vals = append(vals, val)
+		return key > 2
+	})
+	require.Equal(t, []int{3, 2}, keys)
+	assert.Equal(t, []string{"c", "b"}, vals)
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+// 这里的方法可以用于任意的 Map 实现
+
+// PutAll 将 src 中所有的键值对放入 dst，dst 中已经存在的 key 对应的值会被替换
+// 如果要放入一个内置的 map，可以使用 NewBuiltinMapOf 将其包装一下
+func PutAll[K any, V any](dst Map[K, V], src Map[K, V]) error {
+	var err error
+	src.Iterate(func(key K, val V) bool {
+		err = dst.Put(key, val)
+		return err == nil
+	})
+	return err
+}
+
+// ComputeIfAbsent 返回 key 对应的值，
+// key 不存在的时候使用 fn 计算一个值，放入 m 再返回。
+// fn 返回 error 的时候，不会修改 m
+// 注意：这个方法不是原子的，在并发场景下要自己加锁
+func ComputeIfAbsent[K any, V any](m Map[K, V], key K, fn func(key K) (V, error)) (V, error) {
+	val, ok := m.Get(key)
+	if ok {
+		return val, nil
+	}
+	val, err := fn(key)
+	if err != nil {
+		return val, err
+	}
+	return val, m.Put(key, val)
+}
+
+// GetOrDefault 返回 key 对应的值，key 不存在的时候返回 defaultVal
+func GetOrDefault[K any, V any](m Map[K, V], key K, defaultVal V) V {
+	val, ok := m.Get(key)
+	if !ok {
+		return defaultVal
+	}
+	return val
+}
+
+// ContainsKey 判断 m 中是否存在 key
+func ContainsKey[K any, V any](m Map[K, V], key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Clear 删除 m 中所有的键值对
+func Clear[K any, V any](m Map[K, V]) {
+	for _, key := range m.Keys() {
+		m.Delete(key)
+	}
+}
+
+// ToBuiltinMap 将 m 转化为一个内置的 map
+// 每次调用都会返回一个全新的 map
+func ToBuiltinMap[K comparable, V any](m Map[K, V]) map[K]V {
+	res := make(map[K]V, m.Len())
+	m.Iterate(func(key K, val V) bool {
+		res[key] = val
+		return true
+	})
+	return res
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ecodeclub/ekit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutAll(t *testing.T) {
+	testCases := []struct {
+		name    string
+		dst     func(t *testing.T) Map[int, int]
+		src     Map[int, int]
+		want    map[int]int
+		wantErr error
+	}{
+		{
+			name: "builtin map to tree map",
+			dst: func(t *testing.T) Map[int, int] {
+				m, err := NewTreeMap[int, int](ekit.ComparatorRealNumber[int])
+				require.NoError(t, err)
+				require.NoError(t, m.Put(1, 1))
+				return m
+			},
+			src:  NewBuiltinMapOf[int, int](map[int]int{1: 10, 2: 20}),
+			want: map[int]int{1: 10, 2: 20},
+		},
+		{
+			name: "tree map to builtin map",
+			dst: func(t *testing.T) Map[int, int] {
+				return NewBuiltinMap[int, int](0)
+			},
+			src: func() Map[int, int] {
+				m, _ := NewTreeMapWithMap[int, int](ekit.ComparatorRealNumber[int], map[int]int{3: 30})
+				return m
+			}(),
+			want: map[int]int{3: 30},
+		},
+		{
+			name: "empty src",
+			dst: func(t *testing.T) Map[int, int] {
+				return NewBuiltinMapOf[int, int](map[int]int{1: 1})
+			},
+			src:  NewBuiltinMapOf[int, int](nil),
+			want: map[int]int{1: 1},
+		},
+		{
+			name: "put error",
+			dst: func(t *testing.T) Map[int, int] {
+				return errPutMap[int, int]{Map: NewBuiltinMap[int, int](0)}
+			},
+			src:     NewBuiltinMapOf[int, int](map[int]int{1: 10, 2: 20}),
+			want:    map[int]int{},
+			wantErr: errPut,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := tc.dst(t)
+			err := PutAll[int, int](dst, tc.src)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, ToBuiltinMap[int, int](dst))
+		})
+	}
+}
+
+func TestComputeIfAbsent(t *testing.T) {
+	m := NewBuiltinMapOf[string, int](map[string]int{"a": 1})
+	cnt := 0
+	fn := func(key string) (int, error) {
+		cnt++
+		if key == "err" {
+			return 0, errors.New("compute error")
+		}
+		return len(key), nil
+	}
+
+	val, err := ComputeIfAbsent[string, int](m, "a", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, 0, cnt)
+
+	val, err = ComputeIfAbsent[string, int](m, "abc", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 3, val)
+	assert.Equal(t, 1, cnt)
+	// 已经计算过了
+	val, err = ComputeIfAbsent[string, int](m, "abc", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 3, val)
+	assert.Equal(t, 1, cnt)
+
+	_, err = ComputeIfAbsent[string, int](m, "err", fn)
+	assert.Equal(t, errors.New("compute error"), err)
+	assert.False(t, ContainsKey[string, int](m, "err"))
+
+	_, err = ComputeIfAbsent[string, int](errPutMap[string, int]{Map: m}, "put", fn)
+	assert.Equal(t, errPut, err)
+}
+
+func TestGetOrDefault(t *testing.T) {
+	m := NewHashMap[testData, int](10)
+	require.NoError(t, m.Put(newTestData(1), 1))
+	assert.Equal(t, 1, GetOrDefault[testData, int](m, newTestData(1), 100))
+	assert.Equal(t, 100, GetOrDefault[testData, int](m, newTestData(2), 100))
+}
+
+func TestContainsKey(t *testing.T) {
+	m, err := NewLinkedTreeMap[int, int](ekit.ComparatorRealNumber[int])
+	require.NoError(t, err)
+	require.NoError(t, m.Put(1, 0))
+	assert.True(t, ContainsKey[int, int](m, 1))
+	assert.False(t, ContainsKey[int, int](m, 2))
+}
+
+func TestClear(t *testing.T) {
+	m, err := NewTreeMapWithMap[int, int](ekit.ComparatorRealNumber[int], map[int]int{1: 1, 2: 2, 3: 3})
+	require.NoError(t, err)
+	Clear[int, int](m)
+	assert.Equal(t, int64(0), m.Len())
+	assert.Equal(t, map[int]int{}, ToBuiltinMap[int, int](m))
+}
+
+var errPut = errors.New("put error")
+
+// errPutMap Put 总是返回 errPut
+type errPutMap[K any, V any] struct {
+	Map[K, V]
+}
+
+func (e errPutMap[K, V]) Put(key K, val V) error {
+	return errPut
+}
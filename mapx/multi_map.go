@@ -15,41 +15,82 @@
 package mapx
 
 import (
+	"reflect"
+	"sort"
+
 	"github.com/ecodeclub/ekit"
+	"github.com/ecodeclub/ekit/bean/option"
 )
 
 // MultiMap 多映射的 Map
 // 它可以将一个键映射到多个值上
+// 默认情况下同一个键的值按照放入的顺序排列，并且允许重复；
+// 通过 MultiMapWithComparator 可以让同一个键的值保持有序，
+// 通过 MultiMapWithDistinct 可以让同一个键的值不会重复，也就是集合语义
 type MultiMap[K any, V any] struct {
-	m mapi[K, []V]
+	m Map[K, []V]
+	// equal 判断两个值是否相等，为 nil 的时候使用 comparator，comparator 也为 nil 的时候使用 reflect.DeepEqual
+	equal      func(src, dst V) bool
+	comparator ekit.Comparator[V]
+	distinct   bool
+}
+
+// MultiMapWithEqual 指定判断两个值是否相等的方法，用于 DeleteValue、ContainsEntry 和去重
+func MultiMapWithEqual[K any, V any](equal func(src, dst V) bool) option.Option[MultiMap[K, V]] {
+	return func(m *MultiMap[K, V]) {
+		m.equal = equal
+	}
+}
+
+// MultiMapWithComparableEqual 使用 == 判断两个值是否相等
+func MultiMapWithComparableEqual[K any, V comparable]() option.Option[MultiMap[K, V]] {
+	return MultiMapWithEqual[K, V](func(src, dst V) bool {
+		return src == dst
+	})
+}
+
+// MultiMapWithDistinct 同一个键的值不会重复，重复放入的值会被忽略
+// 去重需要和该键已有的每一个值比较，所以每次 Put 的时间复杂度是 O(n)；
+// 默认的 reflect.DeepEqual 比较起来也很慢，V 是 comparable 的时候建议同时使用 MultiMapWithComparableEqual
+func MultiMapWithDistinct[K any, V any]() option.Option[MultiMap[K, V]] {
+	return func(m *MultiMap[K, V]) {
+		m.distinct = true
+	}
+}
+
+// MultiMapWithComparator 同一个键的值按照 comparator 从小到大排列，相等的值按照放入的顺序排列
+func MultiMapWithComparator[K any, V any](comparator ekit.Comparator[V]) option.Option[MultiMap[K, V]] {
+	return func(m *MultiMap[K, V]) {
+		m.comparator = comparator
+	}
 }
 
 // NewMultiTreeMap 创建一个基于 TreeMap 的 MultiMap
 // 注意：
 // - comparator 不能为 nil
-func NewMultiTreeMap[K any, V any](comparator ekit.Comparator[K]) (*MultiMap[K, V], error) {
+func NewMultiTreeMap[K any, V any](comparator ekit.Comparator[K], opts ...option.Option[MultiMap[K, V]]) (*MultiMap[K, V], error) {
 	treeMap, err := NewTreeMap[K, []V](comparator)
 	if err != nil {
 		return nil, err
 	}
-	return &MultiMap[K, V]{
-		m: treeMap,
-	}, nil
+	return newMultiMap[K, V](treeMap, opts...), nil
 }
 
 // NewMultiHashMap 创建一个基于 HashMap 的 MultiMap
-func NewMultiHashMap[K Hashable, V any](size int) *MultiMap[K, V] {
-	var m mapi[K, []V] = NewHashMap[K, []V](size)
-	return &MultiMap[K, V]{
-		m: m,
-	}
+func NewMultiHashMap[K Hashable, V any](size int, opts ...option.Option[MultiMap[K, V]]) *MultiMap[K, V] {
+	return newMultiMap[K, V](NewHashMap[K, []V](size), opts...)
 }
 
-func NewMultiBuiltinMap[K comparable, V any](size int) *MultiMap[K, V] {
-	var m mapi[K, []V] = newBuiltinMap[K, []V](size)
-	return &MultiMap[K, V]{
+func NewMultiBuiltinMap[K comparable, V any](size int, opts ...option.Option[MultiMap[K, V]]) *MultiMap[K, V] {
+	return newMultiMap[K, V](NewBuiltinMap[K, []V](size), opts...)
+}
+
+func newMultiMap[K any, V any](m Map[K, []V], opts ...option.Option[MultiMap[K, V]]) *MultiMap[K, V] {
+	res := &MultiMap[K, V]{
 		m: m,
 	}
+	option.Apply(res, opts...)
+	return res
 }
 
 // Put 在 MultiMap 中添加键值对或向已有键 k 的值追加数据
@@ -58,9 +99,26 @@ func (m *MultiMap[K, V]) Put(k K, v V) error {
 }
 
 // PutMany 在 MultiMap 中添加键值对或向已有键 k 的值追加多个数据
+// 去重模式下，已经存在的值会被忽略
 func (m *MultiMap[K, V]) PutMany(k K, v ...V) error {
 	val, _ := m.Get(k)
-	val = append(val, v...)
+	for _, item := range v {
+		if m.distinct && m.indexOf(val, item) >= 0 {
+			continue
+		}
+		if m.comparator == nil {
+			val = append(val, item)
+			continue
+		}
+		// 插入到所有相等的值的后面，保证稳定
+		idx := sort.Search(len(val), func(i int) bool {
+			return m.comparator(val[i], item) > 0
+		})
+		var zero V
+		val = append(val, zero)
+		copy(val[idx+1:], val[idx:])
+		val[idx] = item
+	}
 	return m.m.Put(k, val)
 }
 
@@ -79,6 +137,42 @@ func (m *MultiMap[K, V]) Delete(k K) ([]V, bool) {
 	return m.m.Delete(k)
 }
 
+// DeleteValue 从键 k 的值中删除第一个和 v 相等的值
+// 如果删除之后键 k 已经没有值了，那么键 k 也会被删除。
+// 返回是否真的删除了值
+func (m *MultiMap[K, V]) DeleteValue(k K, v V) (bool, error) {
+	val, ok := m.m.Get(k)
+	if !ok {
+		return false, nil
+	}
+	idx := m.indexOf(val, v)
+	if idx < 0 {
+		return false, nil
+	}
+	if len(val) == 1 {
+		_, _ = m.m.Delete(k)
+		return true, nil
+	}
+	// val 是内部存储的切片，这里原地修改它；
+	// Get 和 Values 对外返回的都是副本，所以调用者手里的切片不受影响
+	copy(val[idx:], val[idx+1:])
+	var zero V
+	val[len(val)-1] = zero
+	return true, m.m.Put(k, val[:len(val)-1])
+}
+
+// ContainsEntry 判断键 k 的值中是否有和 v 相等的值
+func (m *MultiMap[K, V]) ContainsEntry(k K, v V) bool {
+	val, ok := m.m.Get(k)
+	return ok && m.indexOf(val, v) >= 0
+}
+
+// Count 返回键 k 有多少个值，键 k 不存在的时候返回 0
+func (m *MultiMap[K, V]) Count(k K) int {
+	val, _ := m.m.Get(k)
+	return len(val)
+}
+
 // Keys 返回 MultiMap 所有的键
 func (m *MultiMap[K, V]) Keys() []K {
 	return m.m.Keys()
@@ -100,7 +194,7 @@ func (m *MultiMap[K, V]) Len() int64 {
 }
 
 // Iterate 遍历整个map, 对每个键值对执行cb(k, v), 如果cb的返回值为 true 则继续遍历，否则遍历结束
-// 具体遍历顺序取决于MultiMap包装的mapi的实现
+// 具体遍历顺序取决于MultiMap包装的Map的实现
 func (m *MultiMap[K, V]) Iterate(cb func(K, V) bool) {
 	m.m.Iterate(func(key K, val []V) bool {
 		for _, v := range val {
@@ -111,3 +205,22 @@ func (m *MultiMap[K, V]) Iterate(cb func(K, V) bool) {
 		return true
 	})
 }
+
+func (m *MultiMap[K, V]) indexOf(vals []V, v V) int {
+	for i, val := range vals {
+		if m.isEqual(val, v) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *MultiMap[K, V]) isEqual(src, dst V) bool {
+	if m.equal != nil {
+		return m.equal(src, dst)
+	}
+	if m.comparator != nil {
+		return m.comparator(src, dst) == 0
+	}
+	return reflect.DeepEqual(src, dst)
+}
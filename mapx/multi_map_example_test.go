@@ -17,6 +17,7 @@ package mapx
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/ecodeclub/ekit/tuple/pair"
 )
@@ -53,3 +54,20 @@ func ExampleMultiMap_Iterate() {
 	// world 3
 	// world 4
 }
+
+func ExampleMultiMap_DeleteValue() {
+	// 角色到权限集合的索引，权限不会重复并且按照字典序排列
+	permissions := NewMultiBuiltinMap[string, string](0,
+		MultiMapWithDistinct[string, string](),
+		MultiMapWithComparator[string, string](func(src, dst string) int {
+			return strings.Compare(src, dst)
+		}))
+	_ = permissions.PutMany("admin", "write", "read", "read", "delete")
+	fmt.Println(permissions.Get("admin"))
+
+	_, _ = permissions.DeleteValue("admin", "delete")
+	fmt.Println(permissions.ContainsEntry("admin", "delete"), permissions.Count("admin"))
+	// Output:
+	// [delete read write] true
+	// false 2
+}
@@ -19,7 +19,9 @@ import (
 	"testing"
 
 	"github.com/ecodeclub/ekit"
+	"github.com/ecodeclub/ekit/bean/option"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func getMultiTreeMap() *MultiMap[int, int] {
@@ -741,3 +743,186 @@ func TestMultiMap_Iterate_OnlyIterateHalf(t *testing.T) {
 	assert.Equal(t, n/2, len(arr))
 	sort.Ints(arr)
 }
+
+func TestMultiMap_Options(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []option.Option[MultiMap[string, int]]
+		values []int
+
+		wantValues []int
+	}{
+		{
+			name:       "default",
+			values:     []int{3, 1, 3, 2, 1},
+			wantValues: []int{3, 1, 3, 2, 1},
+		},
+		{
+			name:       "distinct",
+			opts:       []option.Option[MultiMap[string, int]]{MultiMapWithDistinct[string, int]()},
+			values:     []int{3, 1, 3, 2, 1},
+			wantValues: []int{3, 1, 2},
+		},
+		{
+			name:       "comparator",
+			opts:       []option.Option[MultiMap[string, int]]{MultiMapWithComparator[string, int](ekit.ComparatorRealNumber[int])},
+			values:     []int{3, 1, 3, 2, 1},
+			wantValues: []int{1, 1, 2, 3, 3},
+		},
+		{
+			name: "distinct and comparator",
+			opts: []option.Option[MultiMap[string, int]]{
+				MultiMapWithDistinct[string, int](),
+				MultiMapWithComparator[string, int](ekit.ComparatorRealNumber[int]),
+			},
+			values:     []int{3, 1, 3, 2, 1},
+			wantValues: []int{1, 2, 3},
+		},
+		{
+			name: "distinct with equal",
+			opts: []option.Option[MultiMap[string, int]]{
+				MultiMapWithDistinct[string, int](),
+				// 奇偶性相同就认为是相等的
+				MultiMapWithEqual[string, int](func(src, dst int) bool {
+					return src%2 == dst%2
+				}),
+			},
+			values:     []int{3, 1, 3, 2, 4},
+			wantValues: []int{3, 2},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewMultiBuiltinMap[string, int](0, tc.opts...)
+			// 分两次放入，覆盖已经有值的场景
+			require.NoError(t, m.PutMany("key", tc.values[:2]...))
+			for _, v := range tc.values[2:] {
+				require.NoError(t, m.Put("key", v))
+			}
+			val, ok := m.Get("key")
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantValues, val)
+			assert.Equal(t, len(tc.wantValues), m.Count("key"))
+		})
+	}
+}
+
+func TestMultiMap_Comparator_Stable(t *testing.T) {
+	type user struct {
+		age  int
+		name string
+	}
+	m, err := NewMultiTreeMap[int, user](ekit.ComparatorRealNumber[int],
+		MultiMapWithComparator[int, user](func(src, dst user) int {
+			return src.age - dst.age
+		}))
+	require.NoError(t, err)
+	require.NoError(t, m.PutMany(1, user{age: 20, name: "a"}, user{age: 18, name: "b"}))
+	require.NoError(t, m.Put(1, user{age: 20, name: "c"}))
+	require.NoError(t, m.Put(1, user{age: 18, name: "d"}))
+	val, _ := m.Get(1)
+	assert.Equal(t, []user{{18, "b"}, {18, "d"}, {20, "a"}, {20, "c"}}, val)
+
+	// 没有指定 equal 的时候使用 comparator 判断是否相等
+	assert.True(t, m.ContainsEntry(1, user{age: 18}))
+	ok, err := m.DeleteValue(1, user{age: 20})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	val, _ = m.Get(1)
+	assert.Equal(t, []user{{18, "b"}, {18, "d"}, {20, "c"}}, val)
+}
+
+func TestMultiMap_DeleteValue(t *testing.T) {
+	testCases := []struct {
+		name string
+		m    func() *MultiMap[testData, int]
+		key  testData
+		val  int
+
+		wantOk     bool
+		wantValues []int
+		wantLen    int64
+	}{
+		{
+			name: "key not exist",
+			m: func() *MultiMap[testData, int] {
+				return NewMultiHashMap[testData, int](0)
+			},
+			key: newTestData(1),
+			val: 1,
+		},
+		{
+			name: "value not exist",
+			m: func() *MultiMap[testData, int] {
+				m := NewMultiHashMap[testData, int](0)
+				require.NoError(t, m.PutMany(newTestData(1), 1, 2))
+				return m
+			},
+			key:        newTestData(1),
+			val:        3,
+			wantValues: []int{1, 2},
+			wantLen:    1,
+		},
+		{
+			name: "delete first occurrence",
+			m: func() *MultiMap[testData, int] {
+				m := NewMultiHashMap[testData, int](0)
+				require.NoError(t, m.PutMany(newTestData(1), 1, 2, 1, 3))
+				return m
+			},
+			key:        newTestData(1),
+			val:        1,
+			wantOk:     true,
+			wantValues: []int{2, 1, 3},
+			wantLen:    1,
+		},
+		{
+			name: "delete last value",
+			m: func() *MultiMap[testData, int] {
+				m := NewMultiHashMap[testData, int](0)
+				require.NoError(t, m.PutMany(newTestData(1), 1))
+				require.NoError(t, m.PutMany(newTestData(2), 2))
+				return m
+			},
+			key:     newTestData(1),
+			val:     1,
+			wantOk:  true,
+			wantLen: 1,
+		},
+		{
+			name: "comparable equal",
+			m: func() *MultiMap[testData, int] {
+				m := NewMultiHashMap[testData, int](0, MultiMapWithComparableEqual[testData, int]())
+				require.NoError(t, m.PutMany(newTestData(1), 1, 2))
+				return m
+			},
+			key:        newTestData(1),
+			val:        2,
+			wantOk:     true,
+			wantValues: []int{1},
+			wantLen:    1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := tc.m()
+			ok, err := m.DeleteValue(tc.key, tc.val)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantOk, ok)
+			val, _ := m.Get(tc.key)
+			assert.Equal(t, tc.wantValues, val)
+			assert.Equal(t, len(tc.wantValues), m.Count(tc.key))
+			assert.Equal(t, tc.wantLen, m.Len())
+		})
+	}
+}
+
+func TestMultiMap_ContainsEntry(t *testing.T) {
+	m := NewMultiBuiltinMap[string, []string](0)
+	require.NoError(t, m.Put("admin", []string{"read", "write"}))
+	// 默认使用 reflect.DeepEqual，所以切片也可以比较
+	assert.True(t, m.ContainsEntry("admin", []string{"read", "write"}))
+	assert.False(t, m.ContainsEntry("admin", []string{"read"}))
+	assert.False(t, m.ContainsEntry("guest", []string{"read", "write"}))
+	assert.Equal(t, 0, m.Count("guest"))
+}
@@ -0,0 +1,304 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import "math/bits"
+
+const (
+	hamtBits = 5
+	hamtMask = 1<<hamtBits - 1
+)
+
+// PersistentMap 不可变的 Map，基于哈希数组映射字典树（HAMT）实现
+// 所有的修改操作都不会修改原本的 PersistentMap，而是返回一个新的版本，
+// 新旧版本之间共享没有变化的结点，所以修改只需要复制 O(log32(n)) 个结点。
+// 因为不可变，所以可以在多个 goroutine 之间安全地共享，而不需要加锁。
+// 和 HashMap 一样，键需要实现 Hashable 接口。
+// PersistentMap 的零值就是一个空的 Map，可以直接使用
+type PersistentMap[K Hashable, V any] struct {
+	root *hamtNode[K, V]
+	size int64
+}
+
+// hamtNode 每一层使用哈希值中的 5 位作为下标，bitmap 标记了哪些下标有数据，
+// entries 只存储有数据的下标，所以结点是紧凑的
+type hamtNode[K Hashable, V any] struct {
+	bitmap  uint32
+	entries []hamtEntry[K, V]
+}
+
+// hamtEntry 要么是一个子结点，要么是一个叶子
+type hamtEntry[K Hashable, V any] struct {
+	node *hamtNode[K, V]
+	leaf *hamtLeaf[K, V]
+}
+
+// hamtLeaf 存储哈希值完全相同的键值对
+type hamtLeaf[K Hashable, V any] struct {
+	hash uint64
+	kvs  []hamtKV[K, V]
+}
+
+type hamtKV[K Hashable, V any] struct {
+	key K
+	val V
+}
+
+// NewPersistentMap 创建一个空的 PersistentMap
+func NewPersistentMap[K Hashable, V any]() *PersistentMap[K, V] {
+	return &PersistentMap[K, V]{}
+}
+
+// Get 返回 key 对应的值，key 不存在的时候返回 false
+func (m *PersistentMap[K, V]) Get(key K) (V, bool) {
+	hash := key.Code()
+	node := m.root
+	for shift := uint(0); node != nil; shift += hamtBits {
+		bit, pos := node.index(hash, shift)
+		if node.bitmap&bit == 0 {
+			break
+		}
+		entry := node.entries[pos]
+		if entry.leaf != nil {
+			return entry.leaf.get(hash, key)
+		}
+		node = entry.node
+	}
+	var v V
+	return v, false
+}
+
+// Put 返回一个插入了键值对的新版本，如果 key 已经存在，那么新版本中原值会被替换
+func (m *PersistentMap[K, V]) Put(key K, val V) *PersistentMap[K, V] {
+	root := m.root
+	if root == nil {
+		root = &hamtNode[K, V]{}
+	}
+	newRoot, added := root.put(0, key.Code(), key, val)
+	res := &PersistentMap[K, V]{root: newRoot, size: m.size}
+	if added {
+		res.size++
+	}
+	return res
+}
+
+// Delete 返回一个删除了 key 的新版本，以及被删除的值
+// 第三个返回值代表是否真的删除了，key 不存在的时候返回的就是 m 本身
+func (m *PersistentMap[K, V]) Delete(key K) (*PersistentMap[K, V], V, bool) {
+	var v V
+	if m.root == nil {
+		return m, v, false
+	}
+	newRoot, v, ok := m.root.delete(0, key.Code(), key)
+	if !ok {
+		return m, v, false
+	}
+	return &PersistentMap[K, V]{root: newRoot, size: m.size - 1}, v, true
+}
+
+// Keys 返回所有的键，顺序是不确定的
+func (m *PersistentMap[K, V]) Keys() []K {
+	res := make([]K, 0, m.size)
+	m.Iterate(func(key K, val V) bool {
+		res = append(res, key)
+		return true
+	})
+	return res
+}
+
+// Values 返回所有的值，顺序是不确定的
+func (m *PersistentMap[K, V]) Values() []V {
+	res := make([]V, 0, m.size)
+	m.Iterate(func(key K, val V) bool {
+		res = append(res, val)
+		return true
+	})
+	return res
+}
+
+// Len 返回键值对的数量
+func (m *PersistentMap[K, V]) Len() int64 {
+	return m.size
+}
+
+// Iterate 遍历所有的键值对，cb 返回值为 false 的时候结束遍历
+// 同一个版本多次遍历的顺序是一样的
+func (m *PersistentMap[K, V]) Iterate(cb func(key K, val V) bool) {
+	if m.root != nil {
+		m.root.iterate(cb)
+	}
+}
+
+// index 返回 hash 在 shift 这一层的 bit，以及在 entries 中的位置
+func (n *hamtNode[K, V]) index(hash uint64, shift uint) (uint32, int) {
+	bit := uint32(1) << ((hash >> shift) & hamtMask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func (n *hamtNode[K, V]) put(shift uint, hash uint64, key K, val V) (*hamtNode[K, V], bool) {
+	bit, pos := n.index(hash, shift)
+	if n.bitmap&bit == 0 {
+		entries := make([]hamtEntry[K, V], len(n.entries)+1)
+		copy(entries, n.entries[:pos])
+		entries[pos] = hamtEntry[K, V]{leaf: &hamtLeaf[K, V]{hash: hash, kvs: []hamtKV[K, V]{{key: key, val: val}}}}
+		copy(entries[pos+1:], n.entries[pos:])
+		return &hamtNode[K, V]{bitmap: n.bitmap | bit, entries: entries}, true
+	}
+	entry := n.entries[pos]
+	var added bool
+	switch {
+	case entry.node != nil:
+		entry.node, added = entry.node.put(shift+hamtBits, hash, key, val)
+	case entry.leaf.hash == hash:
+		entry.leaf, added = entry.leaf.put(key, val)
+	default:
+		// 哈希值不同，但是在这一层冲突了，需要往下分裂
+		leaf := &hamtLeaf[K, V]{hash: hash, kvs: []hamtKV[K, V]{{key: key, val: val}}}
+		entry = hamtEntry[K, V]{node: mergeHamtLeaves(shift+hamtBits, entry.leaf, leaf)}
+		added = true
+	}
+	return n.with(pos, entry), added
+}
+
+func (n *hamtNode[K, V]) delete(shift uint, hash uint64, key K) (*hamtNode[K, V], V, bool) {
+	var v V
+	bit, pos := n.index(hash, shift)
+	if n.bitmap&bit == 0 {
+		return n, v, false
+	}
+	entry := n.entries[pos]
+	if entry.node != nil {
+		child, v, ok := entry.node.delete(shift+hamtBits, hash, key)
+		if !ok {
+			return n, v, false
+		}
+		switch {
+		case len(child.entries) == 0:
+			return n.without(pos, bit), v, true
+		case len(child.entries) == 1 && child.entries[0].leaf != nil:
+			// 子结点只剩下一个叶子了，把叶子提上来，保持树尽可能矮
+			return n.with(pos, child.entries[0]), v, true
+		default:
+			return n.with(pos, hamtEntry[K, V]{node: child}), v, true
+		}
+	}
+	if entry.leaf.hash != hash {
+		return n, v, false
+	}
+	leaf, v, ok := entry.leaf.delete(key)
+	if !ok {
+		return n, v, false
+	}
+	if leaf == nil {
+		return n.without(pos, bit), v, true
+	}
+	return n.with(pos, hamtEntry[K, V]{leaf: leaf}), v, true
+}
+
+func (n *hamtNode[K, V]) iterate(cb func(key K, val V) bool) bool {
+	for _, entry := range n.entries {
+		if entry.node != nil {
+			if !entry.node.iterate(cb) {
+				return false
+			}
+			continue
+		}
+		for _, kv := range entry.leaf.kvs {
+			if !cb(kv.key, kv.val) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// with 返回 pos 处被替换为 entry 的副本
+func (n *hamtNode[K, V]) with(pos int, entry hamtEntry[K, V]) *hamtNode[K, V] {
+	entries := make([]hamtEntry[K, V], len(n.entries))
+	copy(entries, n.entries)
+	entries[pos] = entry
+	return &hamtNode[K, V]{bitmap: n.bitmap, entries: entries}
+}
+
+// without 返回删除了 pos 处的 entry 的副本
+func (n *hamtNode[K, V]) without(pos int, bit uint32) *hamtNode[K, V] {
+	entries := make([]hamtEntry[K, V], 0, len(n.entries)-1)
+	entries = append(entries, n.entries[:pos]...)
+	entries = append(entries, n.entries[pos+1:]...)
+	return &hamtNode[K, V]{bitmap: n.bitmap &^ bit, entries: entries}
+}
+
+// mergeHamtLeaves 创建一个同时包含两个叶子的结点，两个叶子的哈希值必须不同
+func mergeHamtLeaves[K Hashable, V any](shift uint, l1, l2 *hamtLeaf[K, V]) *hamtNode[K, V] {
+	idx1 := (l1.hash >> shift) & hamtMask
+	idx2 := (l2.hash >> shift) & hamtMask
+	if idx1 == idx2 {
+		return &hamtNode[K, V]{
+			bitmap:  1 << idx1,
+			entries: []hamtEntry[K, V]{{node: mergeHamtLeaves(shift+hamtBits, l1, l2)}},
+		}
+	}
+	if idx1 > idx2 {
+		l1, l2 = l2, l1
+		idx1, idx2 = idx2, idx1
+	}
+	return &hamtNode[K, V]{
+		bitmap:  1<<idx1 | 1<<idx2,
+		entries: []hamtEntry[K, V]{{leaf: l1}, {leaf: l2}},
+	}
+}
+
+func (l *hamtLeaf[K, V]) get(hash uint64, key K) (V, bool) {
+	if l.hash == hash {
+		for _, kv := range l.kvs {
+			if kv.key.Equals(key) {
+				return kv.val, true
+			}
+		}
+	}
+	var v V
+	return v, false
+}
+
+func (l *hamtLeaf[K, V]) put(key K, val V) (*hamtLeaf[K, V], bool) {
+	kvs := make([]hamtKV[K, V], len(l.kvs), len(l.kvs)+1)
+	copy(kvs, l.kvs)
+	for i, kv := range kvs {
+		if kv.key.Equals(key) {
+			kvs[i].val = val
+			return &hamtLeaf[K, V]{hash: l.hash, kvs: kvs}, false
+		}
+	}
+	kvs = append(kvs, hamtKV[K, V]{key: key, val: val})
+	return &hamtLeaf[K, V]{hash: l.hash, kvs: kvs}, true
+}
+
+// delete 删除 key，叶子空了的时候返回 nil
+func (l *hamtLeaf[K, V]) delete(key K) (*hamtLeaf[K, V], V, bool) {
+	for i, kv := range l.kvs {
+		if !kv.key.Equals(key) {
+			continue
+		}
+		if len(l.kvs) == 1 {
+			return nil, kv.val, true
+		}
+		kvs := make([]hamtKV[K, V], 0, len(l.kvs)-1)
+		kvs = append(kvs, l.kvs[:i]...)
+		kvs = append(kvs, l.kvs[i+1:]...)
+		return &hamtLeaf[K, V]{hash: l.hash, kvs: kvs}, kv.val, true
+	}
+	var v V
+	return l, v, false
+}
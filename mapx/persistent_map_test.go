@@ -0,0 +1,196 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersistentMap(t *testing.T) {
+	var m PersistentMap[testData, int]
+	// 零值可以直接使用
+	_, ok := m.Get(newTestData(1))
+	assert.False(t, ok)
+	m2, _, ok := m.Delete(newTestData(1))
+	assert.False(t, ok)
+	assert.Equal(t, &m, m2)
+
+	m1 := m.Put(newTestData(1), 1)
+	// 哈希冲突
+	m2 = m1.Put(newTestData(11), 11)
+	m3 := m2.Put(newTestData(2), 2)
+	// 覆盖
+	m4 := m3.Put(newTestData(1), 100)
+
+	assert.Equal(t, int64(0), m.Len())
+	assertPersistentMap(t, map[int]int{1: 1}, m1)
+	assertPersistentMap(t, map[int]int{1: 1, 11: 11}, m2)
+	assertPersistentMap(t, map[int]int{1: 1, 11: 11, 2: 2}, m3)
+	assertPersistentMap(t, map[int]int{1: 100, 11: 11, 2: 2}, m4)
+
+	m5, val, ok := m4.Delete(newTestData(11))
+	assert.True(t, ok)
+	assert.Equal(t, 11, val)
+	m6, val, ok := m5.Delete(newTestData(1))
+	assert.True(t, ok)
+	assert.Equal(t, 100, val)
+	m7, _, ok := m6.Delete(newTestData(21))
+	assert.False(t, ok)
+	assert.Equal(t, m6, m7)
+
+	assertPersistentMap(t, map[int]int{1: 100, 11: 11, 2: 2}, m4)
+	assertPersistentMap(t, map[int]int{1: 100, 2: 2}, m5)
+	assertPersistentMap(t, map[int]int{2: 2}, m6)
+}
+
+func TestPersistentMap_Split(t *testing.T) {
+	// 低位完全一样，只有高位不同，需要分裂很多层
+	m := NewPersistentMap[hashKey, int]()
+	keys := []hashKey{
+		{hash: 1, id: 1},
+		{hash: 1 | 1<<60, id: 2},
+		{hash: 1 | 1<<63, id: 3},
+		{hash: 1, id: 4},
+	}
+	for i, key := range keys {
+		m = m.Put(key, i)
+	}
+	assert.Equal(t, int64(4), m.Len())
+	for i, key := range keys {
+		val, ok := m.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, i, val)
+	}
+	_, ok := m.Get(hashKey{hash: 1 | 1<<62, id: 5})
+	assert.False(t, ok)
+
+	for i, key := range keys {
+		var val int
+		m, val, ok = m.Delete(key)
+		assert.True(t, ok)
+		assert.Equal(t, i, val)
+		assert.Equal(t, int64(len(keys)-i-1), m.Len())
+	}
+	assert.Equal(t, 0, len(m.root.entries))
+}
+
+// TestPersistentMap_Random 随机操作，和内置 map 的结果进行对比，同时校验旧版本不受影响
+func TestPersistentMap_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	m := NewPersistentMap[hashKey, int]()
+	want := make(map[hashKey]int)
+	type snapshot struct {
+		m    *PersistentMap[hashKey, int]
+		want map[hashKey]int
+	}
+	snapshots := make([]snapshot, 0, 10)
+	for i := 0; i < 20000; i++ {
+		// 只用 12 位的哈希值，制造大量的冲突
+		key := hashKey{hash: uint64(r.Intn(1 << 12)), id: r.Intn(1 << 13)}
+		if r.Intn(3) == 0 {
+			var val int
+			var ok bool
+			m, val, ok = m.Delete(key)
+			wantVal, wantOk := want[key]
+			require.Equal(t, wantOk, ok)
+			require.Equal(t, wantVal, val)
+			delete(want, key)
+		} else {
+			m = m.Put(key, i)
+			want[key] = i
+		}
+		if i%2000 == 0 {
+			copied := make(map[hashKey]int, len(want))
+			for k, v := range want {
+				copied[k] = v
+			}
+			snapshots = append(snapshots, snapshot{m: m, want: copied})
+		}
+	}
+	snapshots = append(snapshots, snapshot{m: m, want: want})
+	for _, s := range snapshots {
+		assert.Equal(t, int64(len(s.want)), s.m.Len())
+		got := make(map[hashKey]int, len(s.want))
+		s.m.Iterate(func(key hashKey, val int) bool {
+			got[key] = val
+			return true
+		})
+		assert.Equal(t, s.want, got)
+		for k, v := range s.want {
+			val, ok := s.m.Get(k)
+			require.True(t, ok)
+			require.Equal(t, v, val)
+		}
+	}
+}
+
+func TestPersistentMap_Iterate(t *testing.T) {
+	m := NewPersistentMap[testData, int]()
+	for i := 0; i < 20; i++ {
+		m = m.Put(newTestData(i), i)
+	}
+	keys := m.Keys()
+	ids := make([]int, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.id)
+	}
+	sort.Ints(ids)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}, ids)
+	vals := m.Values()
+	sort.Ints(vals)
+	assert.Equal(t, ids, vals)
+
+	cnt := 0
+	m.Iterate(func(key testData, val int) bool {
+		cnt++
+		return cnt < 5
+	})
+	assert.Equal(t, 5, cnt)
+}
+
+func assertPersistentMap(t *testing.T, want map[int]int, m *PersistentMap[testData, int]) {
+	assert.Equal(t, int64(len(want)), m.Len())
+	got := make(map[int]int, len(want))
+	m.Iterate(func(key testData, val int) bool {
+		got[key.id] = val
+		return true
+	})
+	assert.Equal(t, want, got)
+	for k, v := range want {
+		val, ok := m.Get(newTestData(k))
+		assert.True(t, ok)
+		assert.Equal(t, v, val)
+	}
+}
+
+// hashKey 可以直接指定哈希值
+type hashKey struct {
+	hash uint64
+	id   int
+}
+
+func (h hashKey) Code() uint64 {
+	return h.hash
+}
+
+func (h hashKey) Equals(key any) bool {
+	val, ok := key.(hashKey)
+	return ok && val == h
+}
@@ -106,4 +106,4 @@ func (treeMap *TreeMap[K, V]) Iterate(cb func(key K, value V) bool) {
 	treeMap.tree.Iterate(cb)
 }
 
-var _ mapi[any, any] = (*TreeMap[any, any])(nil)
+var _ Map[any, any] = (*TreeMap[any, any])(nil)
@@ -52,3 +52,21 @@ func ExampleTreeMap_Iterate() {
 	// -1 12
 	// 1 11
 }
+
+func ExampleTreeMap_SubMap() {
+	m, _ := mapx.NewTreeMap[int, string](ekit.ComparatorRealNumber[int])
+	for i := 1; i <= 5; i++ {
+		_ = m.Put(i*10, fmt.Sprintf("v%d", i))
+	}
+	view := m.SubMap(20, true, 40, false)
+	view.DescendingIterate(func(key int, value string) bool {
+		fmt.Println(key, value)
+		return true
+	})
+	key, _, _ := m.Higher(30)
+	fmt.Println(key)
+	// Output:
+	// 30 v3
+	// 20 v2
+	// 40
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"errors"
+
+	"github.com/ecodeclub/ekit/internal/tree"
+)
+
+var errTreeMapKeyOutOfRange = errors.New("ekit: key 超出了视图的范围")
+
+// FirstKey 返回最小的 key，TreeMap 为空的时候返回 false
+func (treeMap *TreeMap[K, V]) FirstKey() (K, bool) {
+	k, _, ok := treeMap.tree.First()
+	return k, ok
+}
+
+// LastKey 返回最大的 key，TreeMap 为空的时候返回 false
+func (treeMap *TreeMap[K, V]) LastKey() (K, bool) {
+	k, _, ok := treeMap.tree.Last()
+	return k, ok
+}
+
+// Floor 返回 key 小于等于 key 的最大的键值对，不存在的时候返回 false
+func (treeMap *TreeMap[K, V]) Floor(key K) (K, V, bool) {
+	return treeMap.tree.Floor(key)
+}
+
+// Ceiling 返回 key 大于等于 key 的最小的键值对，不存在的时候返回 false
+func (treeMap *TreeMap[K, V]) Ceiling(key K) (K, V, bool) {
+	return treeMap.tree.Ceiling(key)
+}
+
+// Higher 返回 key 严格大于 key 的最小的键值对，不存在的时候返回 false
+func (treeMap *TreeMap[K, V]) Higher(key K) (K, V, bool) {
+	return treeMap.tree.Higher(key)
+}
+
+// Lower 返回 key 严格小于 key 的最大的键值对，不存在的时候返回 false
+func (treeMap *TreeMap[K, V]) Lower(key K) (K, V, bool) {
+	return treeMap.tree.Lower(key)
+}
+
+// PollFirst 删除并返回最小的键值对，TreeMap 为空的时候返回 false
+func (treeMap *TreeMap[K, V]) PollFirst() (K, V, bool) {
+	k, v, ok := treeMap.tree.First()
+	if ok {
+		treeMap.tree.Delete(k)
+	}
+	return k, v, ok
+}
+
+// PollLast 删除并返回最大的键值对，TreeMap 为空的时候返回 false
+func (treeMap *TreeMap[K, V]) PollLast() (K, V, bool) {
+	k, v, ok := treeMap.tree.Last()
+	if ok {
+		treeMap.tree.Delete(k)
+	}
+	return k, v, ok
+}
+
+// DescendingIterate 按照 key 从大到小的顺序遍历并执行 cb，如果 cb 返回值为 false 则结束遍历
+func (treeMap *TreeMap[K, V]) DescendingIterate(cb func(key K, value V) bool) {
+	unbounded := tree.Bound[K]{Unbounded: true}
+	treeMap.tree.DescendRange(unbounded, unbounded, cb)
+}
+
+// SubMap 返回 key 在 from 和 to 之间的视图，fromInclusive 和 toInclusive 决定了是否包含端点
+// 视图和 TreeMap 共享数据，通过视图进行的修改会反映到 TreeMap 上，反之亦然。
+// from 大于 to 的时候，视图总是空的
+func (treeMap *TreeMap[K, V]) SubMap(from K, fromInclusive bool, to K, toInclusive bool) *TreeMapView[K, V] {
+	return &TreeMapView[K, V]{
+		tree: treeMap.tree,
+		lo:   tree.Bound[K]{Key: from, Inclusive: fromInclusive},
+		hi:   tree.Bound[K]{Key: to, Inclusive: toInclusive},
+	}
+}
+
+// HeadMap 返回 key 小于 to 的视图，inclusive 为 true 的时候包含 to
+func (treeMap *TreeMap[K, V]) HeadMap(to K, inclusive bool) *TreeMapView[K, V] {
+	return &TreeMapView[K, V]{
+		tree: treeMap.tree,
+		lo:   tree.Bound[K]{Unbounded: true},
+		hi:   tree.Bound[K]{Key: to, Inclusive: inclusive},
+	}
+}
+
+// TailMap 返回 key 大于 from 的视图，inclusive 为 true 的时候包含 from
+func (treeMap *TreeMap[K, V]) TailMap(from K, inclusive bool) *TreeMapView[K, V] {
+	return &TreeMapView[K, V]{
+		tree: treeMap.tree,
+		lo:   tree.Bound[K]{Key: from, Inclusive: inclusive},
+		hi:   tree.Bound[K]{Unbounded: true},
+	}
+}
+
+var _ Map[any, any] = (*TreeMapView[any, any])(nil)
+
+// TreeMapView 是 TreeMap 中一段范围的视图，通过 SubMap、HeadMap 和 TailMap 创建
+// 所有的操作都只作用于范围之内的键值对
+type TreeMapView[K any, V any] struct {
+	tree *tree.RBTree[K, V]
+	lo   tree.Bound[K]
+	hi   tree.Bound[K]
+}
+
+// Put 插入键值对，key 超出范围的时候返回错误
+func (v *TreeMapView[K, V]) Put(key K, value V) error {
+	if !v.tree.InRange(key, v.lo, v.hi) {
+		return errTreeMapKeyOutOfRange
+	}
+	err := v.tree.Add(key, value)
+	if err == tree.ErrRBTreeSameRBNode {
+		return v.tree.Set(key, value)
+	}
+	return err
+}
+
+// Get 返回 key 对应的值，key 超出范围的时候返回 false
+func (v *TreeMapView[K, V]) Get(key K) (V, bool) {
+	if !v.tree.InRange(key, v.lo, v.hi) {
+		var val V
+		return val, false
+	}
+	val, err := v.tree.Find(key)
+	return val, err == nil
+}
+
+// Delete 删除 key，key 超出范围的时候什么也不做
+func (v *TreeMapView[K, V]) Delete(key K) (V, bool) {
+	if !v.tree.InRange(key, v.lo, v.hi) {
+		var val V
+		return val, false
+	}
+	return v.tree.Delete(key)
+}
+
+// Keys 按照 key 从小到大的顺序返回范围内所有的 key
+func (v *TreeMapView[K, V]) Keys() []K {
+	res := make([]K, 0)
+	v.Iterate(func(key K, value V) bool {
+		res = append(res, key)
+		return true
+	})
+	return res
+}
+
+// Values 按照 key 从小到大的顺序返回范围内所有的值
+func (v *TreeMapView[K, V]) Values() []V {
+	res := make([]V, 0)
+	v.Iterate(func(key K, value V) bool {
+		res = append(res, value)
+		return true
+	})
+	return res
+}
+
+// Len 返回范围内键值对的数量，时间复杂度是 O(logN + M)，M 是范围内键值对的数量
+func (v *TreeMapView[K, V]) Len() int64 {
+	var cnt int64
+	v.Iterate(func(key K, value V) bool {
+		cnt++
+		return true
+	})
+	return cnt
+}
+
+// Iterate 按照 key 从小到大的顺序遍历范围内的键值对
+func (v *TreeMapView[K, V]) Iterate(cb func(key K, value V) bool) {
+	v.tree.AscendRange(v.lo, v.hi, cb)
+}
+
+// DescendingIterate 按照 key 从大到小的顺序遍历范围内的键值对
+func (v *TreeMapView[K, V]) DescendingIterate(cb func(key K, value V) bool) {
+	v.tree.DescendRange(v.lo, v.hi, cb)
+}
+
+// FirstKey 返回范围内最小的 key
+func (v *TreeMapView[K, V]) FirstKey() (K, bool) {
+	var res K
+	var ok bool
+	v.Iterate(func(key K, value V) bool {
+		res, ok = key, true
+		return false
+	})
+	return res, ok
+}
+
+// LastKey 返回范围内最大的 key
+func (v *TreeMapView[K, V]) LastKey() (K, bool) {
+	var res K
+	var ok bool
+	v.DescendingIterate(func(key K, value V) bool {
+		res, ok = key, true
+		return false
+	})
+	return res, ok
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreeMap_Navigable(t *testing.T) {
+	m := newNavigableTreeMap(t)
+	_, ok := m.FirstKey()
+	assert.False(t, ok)
+	_, ok = m.LastKey()
+	assert.False(t, ok)
+	_, _, ok = m.PollFirst()
+	assert.False(t, ok)
+	_, _, ok = m.PollLast()
+	assert.False(t, ok)
+
+	m = newNavigableTreeMap(t, 30, 10, 50, 20, 40)
+	key, ok := m.FirstKey()
+	assert.True(t, ok)
+	assert.Equal(t, 10, key)
+	key, ok = m.LastKey()
+	assert.True(t, ok)
+	assert.Equal(t, 50, key)
+
+	key, val, ok := m.Floor(35)
+	assert.True(t, ok)
+	assert.Equal(t, 30, key)
+	assert.Equal(t, 300, val)
+	key, _, ok = m.Ceiling(35)
+	assert.True(t, ok)
+	assert.Equal(t, 40, key)
+	key, _, ok = m.Higher(40)
+	assert.True(t, ok)
+	assert.Equal(t, 50, key)
+	_, _, ok = m.Higher(50)
+	assert.False(t, ok)
+	key, _, ok = m.Lower(40)
+	assert.True(t, ok)
+	assert.Equal(t, 30, key)
+	_, _, ok = m.Lower(10)
+	assert.False(t, ok)
+
+	key, val, ok = m.PollFirst()
+	assert.True(t, ok)
+	assert.Equal(t, 10, key)
+	assert.Equal(t, 100, val)
+	key, val, ok = m.PollLast()
+	assert.True(t, ok)
+	assert.Equal(t, 50, key)
+	assert.Equal(t, 500, val)
+	assert.Equal(t, []int{20, 30, 40}, m.Keys())
+
+	keys := make([]int, 0, 3)
+	m.DescendingIterate(func(key int, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []int{40, 30, 20}, keys)
+}
+
+func TestTreeMap_SubMap(t *testing.T) {
+	testCases := []struct {
+		name     string
+		view     func(m *TreeMap[int, int]) *TreeMapView[int, int]
+		wantKeys []int
+		inKey    int
+		outKey   int
+	}{
+		{
+			name: "sub map inclusive",
+			view: func(m *TreeMap[int, int]) *TreeMapView[int, int] {
+				return m.SubMap(20, true, 40, true)
+			},
+			wantKeys: []int{20, 30, 40},
+			inKey:    25,
+			outKey:   45,
+		},
+		{
+			name: "sub map exclusive",
+			view: func(m *TreeMap[int, int]) *TreeMapView[int, int] {
+				return m.SubMap(20, false, 40, false)
+			},
+			wantKeys: []int{30},
+			inKey:    35,
+			outKey:   20,
+		},
+		{
+			name: "sub map from greater than to",
+			view: func(m *TreeMap[int, int]) *TreeMapView[int, int] {
+				return m.SubMap(40, true, 20, true)
+			},
+			wantKeys: []int{},
+			outKey:   30,
+		},
+		{
+			name: "head map",
+			view: func(m *TreeMap[int, int]) *TreeMapView[int, int] {
+				return m.HeadMap(30, false)
+			},
+			wantKeys: []int{10, 20},
+			inKey:    5,
+			outKey:   30,
+		},
+		{
+			name: "head map inclusive",
+			view: func(m *TreeMap[int, int]) *TreeMapView[int, int] {
+				return m.HeadMap(30, true)
+			},
+			wantKeys: []int{10, 20, 30},
+			inKey:    25,
+			outKey:   35,
+		},
+		{
+			name: "tail map",
+			view: func(m *TreeMap[int, int]) *TreeMapView[int, int] {
+				return m.TailMap(30, true)
+			},
+			wantKeys: []int{30, 40, 50},
+			inKey:    55,
+			outKey:   25,
+		},
+		{
+			name: "tail map exclusive",
+			view: func(m *TreeMap[int, int]) *TreeMapView[int, int] {
+				return m.TailMap(30, false)
+			},
+			wantKeys: []int{40, 50},
+			inKey:    35,
+			outKey:   30,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newNavigableTreeMap(t, 10, 20, 30, 40, 50)
+			view := tc.view(m)
+			assert.Equal(t, tc.wantKeys, view.Keys())
+			wantVals := make([]int, 0, len(tc.wantKeys))
+			for _, key := range tc.wantKeys {
+				wantVals = append(wantVals, key*10)
+			}
+			assert.Equal(t, wantVals, view.Values())
+			assert.Equal(t, int64(len(tc.wantKeys)), view.Len())
+
+			desc := make([]int, 0, len(tc.wantKeys))
+			view.DescendingIterate(func(key int, value int) bool {
+				desc = append([]int{key}, desc...)
+				return true
+			})
+			assert.Equal(t, tc.wantKeys, desc)
+
+			first, ok := view.FirstKey()
+			last, lastOk := view.LastKey()
+			assert.Equal(t, len(tc.wantKeys) > 0, ok)
+			assert.Equal(t, len(tc.wantKeys) > 0, lastOk)
+			if ok {
+				assert.Equal(t, tc.wantKeys[0], first)
+				assert.Equal(t, tc.wantKeys[len(tc.wantKeys)-1], last)
+			}
+
+			// 范围之外的 key 不能通过视图访问
+			assert.Equal(t, errTreeMapKeyOutOfRange, view.Put(tc.outKey, 1))
+			_, ok = view.Get(tc.outKey)
+			assert.False(t, ok)
+			_, ok = view.Delete(tc.outKey)
+			assert.False(t, ok)
+			if len(tc.wantKeys) == 0 {
+				return
+			}
+
+			// 通过视图修改，会反映到 TreeMap 上
+			require.NoError(t, view.Put(tc.inKey, 1))
+			val, ok := m.Get(tc.inKey)
+			assert.True(t, ok)
+			assert.Equal(t, 1, val)
+			require.NoError(t, view.Put(tc.inKey, 2))
+			val, ok = view.Get(tc.inKey)
+			assert.True(t, ok)
+			assert.Equal(t, 2, val)
+			val, ok = view.Delete(tc.inKey)
+			assert.True(t, ok)
+			assert.Equal(t, 2, val)
+			_, ok = m.Get(tc.inKey)
+			assert.False(t, ok)
+
+			// 通过 TreeMap 修改，也会反映到视图上
+			_, ok = m.Delete(tc.wantKeys[0])
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantKeys[1:], view.Keys())
+		})
+	}
+}
+
+func newNavigableTreeMap(t *testing.T, keys ...int) *TreeMap[int, int] {
+	m, err := NewTreeMap[int, int](compare())
+	require.NoError(t, err)
+	for _, key := range keys {
+		require.NoError(t, m.Put(key, key*10))
+	}
+	return m
+}
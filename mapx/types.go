@@ -14,7 +14,9 @@
 
 package mapx
 
-type mapi[K any, V any] interface {
+// Map 是 mapx 中各种 Map 实现的公共接口，
+// 例如 HashMap、TreeMap、LinkedMap、BuiltinMap 和 ConcurrentSkipListMap
+type Map[K any, V any] interface {
 	Put(key K, val V) error
 	Get(key K) (V, bool)
 	// Delete 删除
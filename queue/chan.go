@@ -0,0 +1,88 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"errors"
+)
+
+// ToChan 启动一个 goroutine，不断地从 q 中出队，并且把元素发送到返回的 channel 中，
+// 这样就可以在 select 里面使用 DelayQueue 之类的阻塞队列。
+// 在 ctx 结束或者 q 被关闭之后，goroutine 会退出，并且关闭返回的两个 channel。
+// 如果因为其它错误而退出，那么该错误会先被发送到 error channel，再关闭 channel。
+// 注意，如果在 ctx 结束的时候，元素已经出队但是还没有被接收，那么该元素会被丢弃
+func ToChan[T any](ctx context.Context, q BlockingQueue[T]) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		defer close(out)
+		for {
+			t, err := q.Dequeue(ctx)
+			if err != nil {
+				if !isNormalExit(ctx, err) {
+					errCh <- err
+				}
+				return
+			}
+			select {
+			case out <- t:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, errCh
+}
+
+// FromChan 启动一个 goroutine，不断地从 ch 中读取元素，并且放入 q 中。
+// 在 ch 被关闭或者 ctx 结束之后，goroutine 会退出，并且关闭返回的 channel。
+// 如果因为入队失败而退出，例如 q 已经被关闭了，那么该错误会先被发送到返回的 channel，再关闭 channel，
+// 这种情况下，入队失败的元素会被丢弃。
+// 调用者可以通过等待返回的 channel 被关闭来确认所有的元素都已经入队
+func FromChan[T any](ctx context.Context, ch <-chan T, q BlockingQueue[T]) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		for {
+			var t T
+			var ok bool
+			select {
+			case t, ok = <-ch:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			if err := q.Enqueue(ctx, t); err != nil {
+				if ctx.Err() == nil || !errors.Is(err, ctx.Err()) {
+					errCh <- err
+				}
+				return
+			}
+		}
+	}()
+	return errCh
+}
+
+// isNormalExit 判断出队失败是不是因为 ctx 结束或者队列被关闭
+func isNormalExit(ctx context.Context, err error) bool {
+	if errors.Is(err, ErrQueueClosed) {
+		return true
+	}
+	return ctx.Err() != nil && errors.Is(err, ctx.Err())
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToChan(t *testing.T) {
+	t.Run("queue closed", func(t *testing.T) {
+		q := NewConcurrentLinkedBlockingQueue[int](0)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, q.Enqueue(ctx, i))
+		}
+		out, errCh := ToChan[int](ctx, q)
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, i, <-out)
+		}
+		require.NoError(t, q.Close())
+		_, ok := <-out
+		assert.False(t, ok)
+		err, ok := <-errCh
+		assert.False(t, ok)
+		assert.NoError(t, err)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		q := NewConcurrentLinkedBlockingQueue[int](0)
+		ctx, cancel := context.WithCancel(context.Background())
+		out, errCh := ToChan[int](ctx, q)
+		cancel()
+		_, ok := <-out
+		assert.False(t, ok)
+		_, ok = <-errCh
+		assert.False(t, ok)
+	})
+
+	t.Run("dequeue error", func(t *testing.T) {
+		q := errBlockingQueue[int]{err: errors.New("mock error")}
+		out, errCh := ToChan[int](context.Background(), q)
+		_, ok := <-out
+		assert.False(t, ok)
+		assert.Equal(t, q.err, <-errCh)
+	})
+}
+
+func TestFromChan(t *testing.T) {
+	t.Run("channel closed", func(t *testing.T) {
+		q := NewConcurrentLinkedBlockingQueue[int](0)
+		ch := make(chan int, 3)
+		for i := 0; i < 3; i++ {
+			ch <- i
+		}
+		close(ch)
+		errCh := FromChan[int](context.Background(), ch, q)
+		err, ok := <-errCh
+		assert.False(t, ok)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 1, 2}, q.AsSlice())
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		q := NewConcurrentLinkedBlockingQueue[int](1)
+		ch := make(chan int, 3)
+		for i := 0; i < 3; i++ {
+			ch <- i
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		// 队列满了之后入队会阻塞，直到超时
+		errCh := FromChan[int](ctx, ch, q)
+		_, ok := <-errCh
+		assert.False(t, ok)
+		assert.Equal(t, []int{0}, q.AsSlice())
+	})
+
+	t.Run("queue closed", func(t *testing.T) {
+		q := NewConcurrentLinkedBlockingQueue[int](0)
+		require.NoError(t, q.Close())
+		ch := make(chan int, 1)
+		ch <- 1
+		errCh := FromChan[int](context.Background(), ch, q)
+		assert.Equal(t, ErrQueueClosed, <-errCh)
+	})
+}
+
+type errBlockingQueue[T any] struct {
+	err error
+}
+
+func (e errBlockingQueue[T]) Enqueue(ctx context.Context, t T) error {
+	return e.err
+}
+
+func (e errBlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	var t T
+	return t, e.err
+}
+
+func ExampleToChan() {
+	q := NewDelayQueue[delayElem](10)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = q.Enqueue(ctx, delayElem{
+		deadline: time.Now().Add(time.Millisecond * 100),
+		val:      1,
+	})
+	out, _ := ToChan[delayElem](ctx, q)
+	select {
+	case ele := <-out:
+		fmt.Println(ele.val)
+	case <-ctx.Done():
+		fmt.Println("timeout")
+	}
+	// Output:
+	// 1
+}
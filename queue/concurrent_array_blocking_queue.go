@@ -17,8 +17,6 @@ package queue
 import (
 	"context"
 	"sync"
-
-	"golang.org/x/sync/semaphore"
 )
 
 // ConcurrentArrayBlockingQueue 有界并发阻塞队列
@@ -33,8 +31,11 @@ type ConcurrentArrayBlockingQueue[T any] struct {
 	// 包含多少个元素
 	count int
 
-	enqueueCap *semaphore.Weighted
-	dequeueCap *semaphore.Weighted
+	notEmpty *cond
+	notFull  *cond
+
+	// closed 为 true 表示队列已经关闭
+	closed bool
 
 	// zero 不能作为返回值返回，防止用户篡改
 	zero T
@@ -45,45 +46,38 @@ type ConcurrentArrayBlockingQueue[T any] struct {
 // capacity 必须为正数
 func NewConcurrentArrayBlockingQueue[T any](capacity int) *ConcurrentArrayBlockingQueue[T] {
 	mutex := &sync.RWMutex{}
-
-	semaForEnqueue := semaphore.NewWeighted(int64(capacity))
-	semaForDequeue := semaphore.NewWeighted(int64(capacity))
-
-	// error暂时不处理，因为目前没办法处理，只能考虑panic掉
-	// 相当于将信号量置空
-	_ = semaForDequeue.Acquire(context.TODO(), int64(capacity))
-
 	res := &ConcurrentArrayBlockingQueue[T]{
-		data:       make([]T, capacity),
-		mutex:      mutex,
-		enqueueCap: semaForEnqueue,
-		dequeueCap: semaForDequeue,
+		data:     make([]T, capacity),
+		mutex:    mutex,
+		notEmpty: newCond(mutex),
+		notFull:  newCond(mutex),
 	}
 	return res
 }
 
 // Enqueue 入队
-// 通过sema来控制容量、超时、阻塞问题
+// 通过 cond 来控制容量、超时、阻塞问题
+// 之所以不使用信号量，是因为阻塞在信号量上的 goroutine 无法被 Close 唤醒
 func (c *ConcurrentArrayBlockingQueue[T]) Enqueue(ctx context.Context, t T) error {
-
-	// 能拿到，说明队列还有空位，可以入队，拿不到则阻塞
-	err := c.enqueueCap.Acquire(ctx, 1)
-
-	if err != nil {
-		return err
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// 拿到锁，先判断是否超时，防止在抢锁时已经超时
 	if ctx.Err() != nil {
-
-		// 超时应该主动归还信号量，避免容量泄露
-		c.enqueueCap.Release(1)
-
 		return ctx.Err()
 	}
+	c.mutex.Lock()
+	// 队列满了就阻塞，直到有空位、超时或者队列被关闭
+	for !c.closed && c.count == len(c.data) {
+		signal := c.notFull.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+			// 收到信号要重新加锁
+			c.mutex.Lock()
+		}
+	}
+	if c.closed {
+		c.mutex.Unlock()
+		return ErrQueueClosed
+	}
 
 	c.data[c.tail] = t
 	c.tail++
@@ -94,37 +88,34 @@ func (c *ConcurrentArrayBlockingQueue[T]) Enqueue(ctx context.Context, t T) erro
 		c.tail = 0
 	}
 
-	// 往出队的sema放入一个元素，出队的goroutine可以拿到并出队
-	c.dequeueCap.Release(1)
-
+	// 通知出队的 goroutine，这里会释放锁
+	c.notEmpty.broadcast()
 	return nil
-
 }
 
 // Dequeue 出队
-// 通过sema来控制容量、超时、阻塞问题
+// 通过 cond 来控制容量、超时、阻塞问题
 func (c *ConcurrentArrayBlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
-
-	// 能拿到，说明队列有元素可以取，可以出队，拿不到则阻塞
-	err := c.dequeueCap.Acquire(ctx, 1)
-
 	var res T
-
-	if err != nil {
-		return res, err
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// 拿到锁，先判断是否超时，防止在抢锁时已经超时
 	if ctx.Err() != nil {
-
-		// 超时应该主动归还信号量，有元素消费不到
-		c.dequeueCap.Release(1)
-
 		return res, ctx.Err()
 	}
+	c.mutex.Lock()
+	// 队列空了就阻塞，直到有元素、超时或者队列被关闭
+	for !c.closed && c.count == 0 {
+		signal := c.notEmpty.signalCh()
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-signal:
+			c.mutex.Lock()
+		}
+	}
+	// 关闭之后，依旧允许把剩余的元素取完
+	if c.count == 0 {
+		c.mutex.Unlock()
+		return res, ErrQueueClosed
+	}
 
 	res = c.data[c.head]
 	// 为了释放内存，GC
@@ -136,13 +127,25 @@ func (c *ConcurrentArrayBlockingQueue[T]) Dequeue(ctx context.Context) (T, error
 		c.head = 0
 	}
 
-	// 往入队的sema放入一个元素，入队的goroutine可以拿到并入队
-	c.enqueueCap.Release(1)
-
+	// 通知入队的 goroutine，这里会释放锁
+	c.notFull.broadcast()
 	return res, nil
-
 }
 
+// Close 关闭队列并唤醒所有阻塞的 goroutine
+// 之后 Enqueue 返回 ErrQueueClosed，Dequeue 取完剩余元素之后返回 ErrQueueClosed
+func (c *ConcurrentArrayBlockingQueue[T]) Close() error {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.notEmpty.broadcast()
+	c.mutex.Lock()
+	c.notFull.broadcast()
+	return nil
+}
 func (c *ConcurrentArrayBlockingQueue[T]) Len() int {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
@@ -411,6 +411,62 @@ func TestConcurrentArrayBlockingQueue(t *testing.T) {
 	wg.Wait()
 }
 
+func TestConcurrentArrayBlockingQueue_Close(t *testing.T) {
+	t.Run("enqueue after close", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](3)
+		require.NoError(t, q.Close())
+		// 重复关闭没有影响
+		require.NoError(t, q.Close())
+		err := q.Enqueue(context.Background(), 123)
+		assert.Equal(t, ErrQueueClosed, err)
+	})
+
+	t.Run("drain after close", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](3)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, 123))
+		require.NoError(t, q.Enqueue(ctx, 234))
+		require.NoError(t, q.Close())
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 123, val)
+		val, err = q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 234, val)
+		_, err = q.Dequeue(ctx)
+		assert.Equal(t, ErrQueueClosed, err)
+	})
+
+	// 阻塞在出队上的 goroutine 会被 Close 唤醒
+	t.Run("close while dequeue blocking", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](3)
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			_ = q.Close()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := q.Dequeue(ctx)
+		assert.Equal(t, ErrQueueClosed, err)
+	})
+
+	// 阻塞在入队上的 goroutine 会被 Close 唤醒
+	t.Run("close while enqueue blocking", func(t *testing.T) {
+		q := NewConcurrentArrayBlockingQueue[int](1)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, 123))
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			_ = q.Close()
+		}()
+		err := q.Enqueue(ctx, 234)
+		assert.Equal(t, ErrQueueClosed, err)
+		assert.Equal(t, []int{123}, q.AsSlice())
+	})
+}
+
 func ExampleNewConcurrentArrayBlockingQueue() {
 	q := NewConcurrentArrayBlockingQueue[int](10)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
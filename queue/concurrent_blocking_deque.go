@@ -0,0 +1,184 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+var _ BlockingQueue[any] = &ConcurrentBlockingDeque[any]{}
+
+// ConcurrentBlockingDeque 基于 Deque 的并发阻塞双端队列
+// 如果 capacity 是正数，那么就是有界队列，队列满了的时候入队会阻塞；
+// 如果不是，就是无界队列，在这种情况下，入队永远能够成功
+// 队列为空的时候出队会阻塞
+// 它同时实现了 BlockingQueue 接口，Enqueue 等价于 PushBack，Dequeue 等价于 PopFront
+type ConcurrentBlockingDeque[T any] struct {
+	mutex *sync.RWMutex
+
+	// 最大容量
+	maxSize int
+	deque   *Deque[T]
+
+	notEmpty *cond
+	notFull  *cond
+
+	// closed 为 true 表示队列已经关闭
+	closed bool
+}
+
+// NewConcurrentBlockingDeque 创建阻塞双端队列 capacity <= 0 时，为无界队列
+func NewConcurrentBlockingDeque[T any](capacity int) *ConcurrentBlockingDeque[T] {
+	mutex := &sync.RWMutex{}
+	return &ConcurrentBlockingDeque[T]{
+		mutex:    mutex,
+		maxSize:  capacity,
+		deque:    NewDeque[T](0),
+		notEmpty: newCond(mutex),
+		notFull:  newCond(mutex),
+	}
+}
+
+// PushFront 在队头放入元素，队列满了的时候会阻塞
+func (c *ConcurrentBlockingDeque[T]) PushFront(ctx context.Context, t T) error {
+	if err := c.waitNotFull(ctx); err != nil {
+		return err
+	}
+	c.deque.PushFront(t)
+	// 这里会释放锁
+	c.notEmpty.broadcast()
+	return nil
+}
+
+// PushBack 在队尾放入元素，队列满了的时候会阻塞
+func (c *ConcurrentBlockingDeque[T]) PushBack(ctx context.Context, t T) error {
+	if err := c.waitNotFull(ctx); err != nil {
+		return err
+	}
+	c.deque.PushBack(t)
+	c.notEmpty.broadcast()
+	return nil
+}
+
+// PopFront 从队头取出元素，队列为空的时候会阻塞
+func (c *ConcurrentBlockingDeque[T]) PopFront(ctx context.Context) (T, error) {
+	if err := c.waitNotEmpty(ctx); err != nil {
+		var t T
+		return t, err
+	}
+	val, err := c.deque.PopFront()
+	c.notFull.broadcast()
+	return val, err
+}
+
+// PopBack 从队尾取出元素，队列为空的时候会阻塞
+func (c *ConcurrentBlockingDeque[T]) PopBack(ctx context.Context) (T, error) {
+	if err := c.waitNotEmpty(ctx); err != nil {
+		var t T
+		return t, err
+	}
+	val, err := c.deque.PopBack()
+	c.notFull.broadcast()
+	return val, err
+}
+
+// Enqueue 等价于 PushBack
+func (c *ConcurrentBlockingDeque[T]) Enqueue(ctx context.Context, t T) error {
+	return c.PushBack(ctx, t)
+}
+
+// Dequeue 等价于 PopFront
+func (c *ConcurrentBlockingDeque[T]) Dequeue(ctx context.Context) (T, error) {
+	return c.PopFront(ctx)
+}
+
+// Close 关闭队列
+// 关闭之后入队总是返回 ErrQueueClosed，
+// 出队会继续返回队列中剩余的元素，取完之后返回 ErrQueueClosed。
+// 所有阻塞的 goroutine 都会被唤醒。
+// 重复调用 Close 不会有任何效果
+func (c *ConcurrentBlockingDeque[T]) Close() error {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.notEmpty.broadcast()
+	c.mutex.Lock()
+	c.notFull.broadcast()
+	return nil
+}
+
+func (c *ConcurrentBlockingDeque[T]) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.Len()
+}
+
+func (c *ConcurrentBlockingDeque[T]) AsSlice() []T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.AsSlice()
+}
+
+// waitNotFull 加锁并等待队列有空位
+// 返回 nil 的时候依旧持有锁，否则锁已经被释放
+func (c *ConcurrentBlockingDeque[T]) waitNotFull(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	c.mutex.Lock()
+	for !c.closed && c.maxSize > 0 && c.deque.Len() == c.maxSize {
+		signal := c.notFull.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+			// 收到信号要重新加锁
+			c.mutex.Lock()
+		}
+	}
+	if c.closed {
+		c.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	return nil
+}
+
+// waitNotEmpty 加锁并等待队列有元素
+// 返回 nil 的时候依旧持有锁，否则锁已经被释放
+func (c *ConcurrentBlockingDeque[T]) waitNotEmpty(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	c.mutex.Lock()
+	for !c.closed && c.deque.Len() == 0 {
+		signal := c.notEmpty.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+			c.mutex.Lock()
+		}
+	}
+	// 关闭之后，依旧允许把剩余的元素取完
+	if c.deque.Len() == 0 {
+		c.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentBlockingDeque_Push(t *testing.T) {
+	testCases := []struct {
+		name      string
+		d         func() *ConcurrentBlockingDeque[int]
+		push      func(d *ConcurrentBlockingDeque[int], ctx context.Context) error
+		timeout   time.Duration
+		wantErr   error
+		wantSlice []int
+	}{
+		{
+			name: "push front",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 3, 2, 3)
+			},
+			push: func(d *ConcurrentBlockingDeque[int], ctx context.Context) error {
+				return d.PushFront(ctx, 1)
+			},
+			timeout:   time.Second,
+			wantSlice: []int{1, 2, 3},
+		},
+		{
+			name: "push back",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 3, 1, 2)
+			},
+			push: func(d *ConcurrentBlockingDeque[int], ctx context.Context) error {
+				return d.PushBack(ctx, 3)
+			},
+			timeout:   time.Second,
+			wantSlice: []int{1, 2, 3},
+		},
+		{
+			name: "invalid context",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 3)
+			},
+			push: func(d *ConcurrentBlockingDeque[int], ctx context.Context) error {
+				return d.PushFront(ctx, 1)
+			},
+			timeout:   -time.Second,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{},
+		},
+		{
+			name: "full and timeout",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 2, 1, 2)
+			},
+			push: func(d *ConcurrentBlockingDeque[int], ctx context.Context) error {
+				return d.PushFront(ctx, 0)
+			},
+			timeout:   time.Millisecond * 100,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{1, 2},
+		},
+		{
+			// 无界队列入队总是能成功
+			name: "unbounded",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 0, 1, 2)
+			},
+			push: func(d *ConcurrentBlockingDeque[int], ctx context.Context) error {
+				return d.Enqueue(ctx, 3)
+			},
+			timeout:   time.Second,
+			wantSlice: []int{1, 2, 3},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			d := tc.d()
+			err := tc.push(d, ctx)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, d.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), d.Len())
+		})
+	}
+
+	// 入队阻塞，而后出队，于是入队成功
+	t.Run("push blocking and pop", func(t *testing.T) {
+		d := newConcurrentBlockingDeque(t, 2, 1, 2)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			val, err := d.PopBack(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, val)
+		}()
+		err := d.PushFront(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, 1}, d.AsSlice())
+	})
+}
+
+func TestConcurrentBlockingDeque_Pop(t *testing.T) {
+	testCases := []struct {
+		name      string
+		d         func() *ConcurrentBlockingDeque[int]
+		pop       func(d *ConcurrentBlockingDeque[int], ctx context.Context) (int, error)
+		timeout   time.Duration
+		wantVal   int
+		wantErr   error
+		wantSlice []int
+	}{
+		{
+			name: "pop front",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 3, 1, 2, 3)
+			},
+			pop: func(d *ConcurrentBlockingDeque[int], ctx context.Context) (int, error) {
+				return d.PopFront(ctx)
+			},
+			timeout:   time.Second,
+			wantVal:   1,
+			wantSlice: []int{2, 3},
+		},
+		{
+			name: "pop back",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 3, 1, 2, 3)
+			},
+			pop: func(d *ConcurrentBlockingDeque[int], ctx context.Context) (int, error) {
+				return d.PopBack(ctx)
+			},
+			timeout:   time.Second,
+			wantVal:   3,
+			wantSlice: []int{1, 2},
+		},
+		{
+			name: "dequeue",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 3, 1, 2, 3)
+			},
+			pop: func(d *ConcurrentBlockingDeque[int], ctx context.Context) (int, error) {
+				return d.Dequeue(ctx)
+			},
+			timeout:   time.Second,
+			wantVal:   1,
+			wantSlice: []int{2, 3},
+		},
+		{
+			name: "invalid context",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 3, 1)
+			},
+			pop: func(d *ConcurrentBlockingDeque[int], ctx context.Context) (int, error) {
+				return d.PopBack(ctx)
+			},
+			timeout:   -time.Second,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{1},
+		},
+		{
+			name: "empty and timeout",
+			d: func() *ConcurrentBlockingDeque[int] {
+				return newConcurrentBlockingDeque(t, 3)
+			},
+			pop: func(d *ConcurrentBlockingDeque[int], ctx context.Context) (int, error) {
+				return d.PopFront(ctx)
+			},
+			timeout:   time.Millisecond * 100,
+			wantErr:   context.DeadlineExceeded,
+			wantSlice: []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			d := tc.d()
+			val, err := tc.pop(d, ctx)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantSlice, d.AsSlice())
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val)
+		})
+	}
+
+	t.Run("pop blocking and push", func(t *testing.T) {
+		d := newConcurrentBlockingDeque(t, 2)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			assert.NoError(t, d.PushBack(ctx, 1))
+		}()
+		val, err := d.PopBack(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, val)
+	})
+}
+
+func TestConcurrentBlockingDeque_Close(t *testing.T) {
+	d := newConcurrentBlockingDeque(t, 2, 1, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, d.Close())
+	require.NoError(t, d.Close())
+	assert.Equal(t, ErrQueueClosed, d.PushFront(ctx, 0))
+	assert.Equal(t, ErrQueueClosed, d.PushBack(ctx, 3))
+	val, err := d.PopBack(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, val)
+	val, err = d.PopFront(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	_, err = d.PopFront(ctx)
+	assert.Equal(t, ErrQueueClosed, err)
+
+	// 阻塞的 goroutine 会被唤醒
+	d = newConcurrentBlockingDeque(t, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := d.PopBack(ctx)
+		assert.Equal(t, ErrQueueClosed, err)
+	}()
+	time.Sleep(time.Millisecond * 100)
+	require.NoError(t, d.Close())
+	wg.Wait()
+}
+
+func TestConcurrentBlockingDeque(t *testing.T) {
+	// 并发测试，只是测试有没有死锁之类的问题
+	d := NewConcurrentBlockingDeque[int](10)
+	var wg sync.WaitGroup
+	wg.Add(200)
+	for i := 0; i < 100; i++ {
+		go func(i int) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			var err error
+			if i%2 == 0 {
+				err = d.PushFront(ctx, i)
+			} else {
+				err = d.PushBack(ctx, i)
+			}
+			assert.NoError(t, err)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			var err error
+			if i%2 == 0 {
+				_, err = d.PopFront(ctx)
+			} else {
+				_, err = d.PopBack(ctx)
+			}
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 0, d.Len())
+}
+
+func newConcurrentBlockingDeque(t *testing.T, capacity int, vals ...int) *ConcurrentBlockingDeque[int] {
+	d := NewConcurrentBlockingDeque[int](capacity)
+	for _, val := range vals {
+		err := d.PushBack(context.Background(), val)
+		require.NoError(t, err)
+	}
+	return d
+}
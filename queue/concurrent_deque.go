@@ -0,0 +1,92 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import "sync"
+
+// ConcurrentDeque 用读写锁封装了对 Deque 的操作，达到线程安全的目标
+// 它不会阻塞，队列为空的时候出队直接返回 ErrEmptyQueue
+// 如果需要阻塞，请使用 ConcurrentBlockingDeque
+type ConcurrentDeque[T any] struct {
+	deque *Deque[T]
+	mutex sync.RWMutex
+}
+
+// NewConcurrentDeque 创建一个并发安全的双端队列，capacity 是初始容量
+func NewConcurrentDeque[T any](capacity int) *ConcurrentDeque[T] {
+	return &ConcurrentDeque[T]{
+		deque: NewDeque[T](capacity),
+	}
+}
+
+func (c *ConcurrentDeque[T]) PushFront(t T) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.deque.PushFront(t)
+}
+
+func (c *ConcurrentDeque[T]) PushBack(t T) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.deque.PushBack(t)
+}
+
+func (c *ConcurrentDeque[T]) PopFront() (T, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.deque.PopFront()
+}
+
+func (c *ConcurrentDeque[T]) PopBack() (T, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.deque.PopBack()
+}
+
+func (c *ConcurrentDeque[T]) PeekFront() (T, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.PeekFront()
+}
+
+func (c *ConcurrentDeque[T]) PeekBack() (T, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.PeekBack()
+}
+
+func (c *ConcurrentDeque[T]) Get(index int) (T, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.Get(index)
+}
+
+func (c *ConcurrentDeque[T]) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.Len()
+}
+
+func (c *ConcurrentDeque[T]) Cap() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.Cap()
+}
+
+func (c *ConcurrentDeque[T]) AsSlice() []T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.deque.AsSlice()
+}
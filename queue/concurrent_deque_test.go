@@ -0,0 +1,83 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentDeque(t *testing.T) {
+	d := NewConcurrentDeque[int](0)
+	_, err := d.PeekFront()
+	assert.Equal(t, ErrEmptyQueue, err)
+	d.PushBack(2)
+	d.PushFront(1)
+	d.PushBack(3)
+	assert.Equal(t, []int{1, 2, 3}, d.AsSlice())
+	assert.Equal(t, 3, d.Len())
+	assert.Equal(t, defaultDequeCapacity, d.Cap())
+	val, err := d.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, val)
+	val, err = d.PeekBack()
+	require.NoError(t, err)
+	assert.Equal(t, 3, val)
+	val, err = d.PopBack()
+	require.NoError(t, err)
+	assert.Equal(t, 3, val)
+	val, err = d.PopFront()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+
+	// 并发测试，只是测试有没有死锁或者数据竞争之类的问题
+	d = NewConcurrentDeque[int](0)
+	var wg sync.WaitGroup
+	wg.Add(100)
+	for i := 0; i < 100; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if i%2 == 0 {
+					d.PushFront(j)
+				} else {
+					d.PushBack(j)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 10000, d.Len())
+	wg.Add(100)
+	for i := 0; i < 100; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				var err error
+				if i%2 == 0 {
+					_, err = d.PopFront()
+				} else {
+					_, err = d.PopBack()
+				}
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 0, d.Len())
+}
@@ -34,6 +34,9 @@ type ConcurrentLinkedBlockingQueue[T any] struct {
 
 	notEmpty *cond
 	notFull  *cond
+
+	// closed 为 true 表示队列已经关闭
+	closed bool
 }
 
 // NewConcurrentLinkedBlockingQueue 创建链式阻塞队列 capacity <= 0 时，为无界队列
@@ -52,11 +55,36 @@ func NewConcurrentLinkedBlockingQueue[T any](capacity int) *ConcurrentLinkedBloc
 // Enqueue 入队
 // 注意：目前我们已经通过broadcast实现了超时控制
 func (c *ConcurrentLinkedBlockingQueue[T]) Enqueue(ctx context.Context, t T) error {
+	if err := c.waitNotFull(ctx); err != nil {
+		return err
+	}
+	err := c.linkedlist.Append(t)
+
+	// 这里会释放锁
+	c.notEmpty.broadcast()
+	return err
+}
+
+// Dequeue 出队
+// 注意：目前我们已经通过broadcast实现了超时控制
+func (c *ConcurrentLinkedBlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	if err := c.waitNotEmpty(ctx); err != nil {
+		var t T
+		return t, err
+	}
+	val, err := c.linkedlist.Delete(0)
+	c.notFull.broadcast()
+	return val, err
+}
+
+// waitNotFull 加锁并等待队列有空位
+// 返回 nil 的时候依旧持有锁，否则锁已经被释放
+func (c *ConcurrentLinkedBlockingQueue[T]) waitNotFull(ctx context.Context) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 	c.mutex.Lock()
-	for c.maxSize > 0 && c.linkedlist.Len() == c.maxSize {
+	for !c.closed && c.maxSize > 0 && c.linkedlist.Len() == c.maxSize {
 		signal := c.notFull.signalCh()
 		select {
 		case <-ctx.Done():
@@ -66,36 +94,49 @@ func (c *ConcurrentLinkedBlockingQueue[T]) Enqueue(ctx context.Context, t T) err
 			c.mutex.Lock()
 		}
 	}
-
-	err := c.linkedlist.Append(t)
-
-	// 这里会释放锁
-	c.notEmpty.broadcast()
-	return err
+	if c.closed {
+		c.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	return nil
 }
 
-// Dequeue 出队
-// 注意：目前我们已经通过broadcast实现了超时控制
-func (c *ConcurrentLinkedBlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
+// waitNotEmpty 加锁并等待队列有元素
+// 返回 nil 的时候依旧持有锁，否则锁已经被释放
+func (c *ConcurrentLinkedBlockingQueue[T]) waitNotEmpty(ctx context.Context) error {
 	if ctx.Err() != nil {
-		var t T
-		return t, ctx.Err()
+		return ctx.Err()
 	}
 	c.mutex.Lock()
-	for c.linkedlist.Len() == 0 {
+	for !c.closed && c.linkedlist.Len() == 0 {
 		signal := c.notEmpty.signalCh()
 		select {
 		case <-ctx.Done():
-			var t T
-			return t, ctx.Err()
+			return ctx.Err()
 		case <-signal:
 			c.mutex.Lock()
 		}
 	}
+	// 关闭之后，依旧允许把剩余的元素取完
+	if c.linkedlist.Len() == 0 {
+		c.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	return nil
+}
 
-	val, err := c.linkedlist.Delete(0)
+// Close 关闭队列，语义和 ConcurrentArrayBlockingQueue.Close 一样
+func (c *ConcurrentLinkedBlockingQueue[T]) Close() error {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.notEmpty.broadcast()
+	c.mutex.Lock()
 	c.notFull.broadcast()
-	return val, err
+	return nil
 }
 
 func (c *ConcurrentLinkedBlockingQueue[T]) Len() int {
@@ -0,0 +1,164 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+
+	"github.com/ecodeclub/ekit/bean/option"
+)
+
+var _ BlockingQueue[any] = &DedupBlockingQueue[int, any]{}
+
+// DedupBlockingQueue 去重阻塞队列，基于 ConcurrentLinkedBlockingQueue 实现
+// 如果队列中已经有一个 key 相同的元素在等待出队，那么入队会被忽略，
+// 或者在指定了 merge 的情况下和已有的元素合并，合并之后的元素保持原来的位置。
+// 元素出队之后，key 被释放，相同 key 的元素可以再次入队
+type DedupBlockingQueue[K comparable, T any] struct {
+	q       *ConcurrentLinkedBlockingQueue[*dedupEntry[K, T]]
+	keyFunc func(t T) K
+	merge   func(old T, new T) T
+	// pending 在队列中等待出队的元素，由 q.mutex 保护
+	pending map[K]*dedupEntry[K, T]
+}
+
+type dedupEntry[K comparable, T any] struct {
+	key K
+	val T
+}
+
+// NewDedupBlockingQueue 创建去重阻塞队列 capacity <= 0 时，为无界队列
+// keyFunc 用于计算元素的 key，key 相同的元素被认为是重复的
+func NewDedupBlockingQueue[K comparable, T any](capacity int, keyFunc func(t T) K,
+	opts ...option.Option[DedupBlockingQueue[K, T]]) *DedupBlockingQueue[K, T] {
+	res := &DedupBlockingQueue[K, T]{
+		q:       NewConcurrentLinkedBlockingQueue[*dedupEntry[K, T]](capacity),
+		keyFunc: keyFunc,
+		pending: make(map[K]*dedupEntry[K, T]),
+	}
+	option.Apply(res, opts...)
+	return res
+}
+
+// DedupBlockingQueueWithMerge 指定合并重复元素的方式
+// old 是已经在队列中的元素，new 是新入队的元素，返回值会替换队列中的元素
+// 不指定的时候，重复的元素会被直接忽略
+func DedupBlockingQueueWithMerge[K comparable, T any](merge func(old T, new T) T) option.Option[DedupBlockingQueue[K, T]] {
+	return func(q *DedupBlockingQueue[K, T]) {
+		q.merge = merge
+	}
+}
+
+// Enqueue 入队
+// 如果已经有相同 key 的元素在等待出队，那么直接返回 nil，即便队列已经满了也不会阻塞
+func (d *DedupBlockingQueue[K, T]) Enqueue(ctx context.Context, t T) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	key := d.keyFunc(t)
+	q := d.q
+	q.mutex.Lock()
+	// 等待的过程中，可能已经有相同 key 的元素入队了，所以每次醒来都要重新检查
+	for !q.closed && !d.mergeIfPending(key, t) {
+		if q.maxSize <= 0 || q.linkedlist.Len() < q.maxSize {
+			entry := &dedupEntry[K, T]{key: key, val: t}
+			err := q.linkedlist.Append(entry)
+			if err != nil {
+				q.mutex.Unlock()
+				return err
+			}
+			d.pending[key] = entry
+			// 这里会释放锁
+			q.notEmpty.broadcast()
+			if q.maxSize > 0 {
+				// 唤醒等待同一个 key 的入队者，它们不需要再等待空位了
+				q.mutex.Lock()
+				q.notFull.broadcast()
+			}
+			return nil
+		}
+		signal := q.notFull.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+			// 收到信号要重新加锁
+			q.mutex.Lock()
+		}
+	}
+	if q.closed {
+		q.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	q.mutex.Unlock()
+	return nil
+}
+
+// Dequeue 出队，并且释放元素的 key
+func (d *DedupBlockingQueue[K, T]) Dequeue(ctx context.Context) (T, error) {
+	if err := d.q.waitNotEmpty(ctx); err != nil {
+		var t T
+		return t, err
+	}
+	entry, err := d.q.linkedlist.Delete(0)
+	if err != nil {
+		d.q.mutex.Unlock()
+		var t T
+		return t, err
+	}
+	delete(d.pending, entry.key)
+	d.q.notFull.broadcast()
+	return entry.val, nil
+}
+
+// Contains 判断是否有 key 相同的元素在等待出队
+func (d *DedupBlockingQueue[K, T]) Contains(key K) bool {
+	d.q.mutex.RLock()
+	defer d.q.mutex.RUnlock()
+	_, ok := d.pending[key]
+	return ok
+}
+
+func (d *DedupBlockingQueue[K, T]) Len() int {
+	return d.q.Len()
+}
+
+func (d *DedupBlockingQueue[K, T]) AsSlice() []T {
+	d.q.mutex.RLock()
+	defer d.q.mutex.RUnlock()
+	res := make([]T, 0, d.q.linkedlist.Len())
+	_ = d.q.linkedlist.Range(func(index int, entry *dedupEntry[K, T]) error {
+		res = append(res, entry.val)
+		return nil
+	})
+	return res
+}
+
+// Close 关闭队列，语义和 ConcurrentLinkedBlockingQueue.Close 一样
+func (d *DedupBlockingQueue[K, T]) Close() error {
+	return d.q.Close()
+}
+
+// mergeIfPending 如果 key 已经在队列中，那么合并元素并且返回 true，必须在锁范围内调用
+func (d *DedupBlockingQueue[K, T]) mergeIfPending(key K, t T) bool {
+	entry, ok := d.pending[key]
+	if !ok {
+		return false
+	}
+	if d.merge != nil {
+		entry.val = d.merge(entry.val, t)
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/bean/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupBlockingQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name      string
+		capacity  int
+		opts      []option.Option[DedupBlockingQueue[string, refreshTask]]
+		tasks     []refreshTask
+		wantSlice []refreshTask
+	}{
+		{
+			name: "ignore duplicate",
+			tasks: []refreshTask{
+				{key: "a", version: 1},
+				{key: "b", version: 1},
+				{key: "a", version: 2},
+			},
+			wantSlice: []refreshTask{
+				{key: "a", version: 1},
+				{key: "b", version: 1},
+			},
+		},
+		{
+			name: "merge duplicate",
+			opts: []option.Option[DedupBlockingQueue[string, refreshTask]]{
+				DedupBlockingQueueWithMerge[string, refreshTask](func(old refreshTask, new refreshTask) refreshTask {
+					if new.version > old.version {
+						return new
+					}
+					return old
+				}),
+			},
+			tasks: []refreshTask{
+				{key: "a", version: 2},
+				{key: "b", version: 1},
+				{key: "a", version: 3},
+				{key: "a", version: 1},
+			},
+			// 合并之后保持原来的位置
+			wantSlice: []refreshTask{
+				{key: "a", version: 3},
+				{key: "b", version: 1},
+			},
+		},
+		{
+			// 队列满了，但是重复的元素依旧可以入队
+			name:     "full but duplicate",
+			capacity: 1,
+			tasks: []refreshTask{
+				{key: "a", version: 1},
+				{key: "a", version: 2},
+			},
+			wantSlice: []refreshTask{
+				{key: "a", version: 1},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewDedupBlockingQueue[string, refreshTask](tc.capacity, refreshTaskKey, tc.opts...)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			for _, task := range tc.tasks {
+				require.NoError(t, q.Enqueue(ctx, task))
+			}
+			assert.Equal(t, tc.wantSlice, q.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), q.Len())
+		})
+	}
+
+	t.Run("invalid context", func(t *testing.T) {
+		q := NewDedupBlockingQueue[string, refreshTask](0, refreshTaskKey)
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		err := q.Enqueue(ctx, refreshTask{key: "a"})
+		assert.Equal(t, context.DeadlineExceeded, err)
+		_, err = q.Dequeue(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("full and timeout", func(t *testing.T) {
+		q := NewDedupBlockingQueue[string, refreshTask](1, refreshTaskKey)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, refreshTask{key: "a"}))
+		err := q.Enqueue(ctx, refreshTask{key: "b"})
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.False(t, q.Contains("b"))
+	})
+
+	// 等待的过程中，相同的 key 被别人先放进去了
+	t.Run("duplicate while waiting", func(t *testing.T) {
+		q := NewDedupBlockingQueue[string, refreshTask](1, refreshTaskKey)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, refreshTask{key: "a"}))
+		var wg sync.WaitGroup
+		wg.Add(2)
+		for i := 0; i < 2; i++ {
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, q.Enqueue(ctx, refreshTask{key: "b", version: i}))
+			}(i)
+		}
+		time.Sleep(time.Millisecond * 100)
+		task, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "a", task.key)
+		wg.Wait()
+		assert.Equal(t, 1, q.Len())
+	})
+}
+
+func TestDedupBlockingQueue_Dequeue(t *testing.T) {
+	q := NewDedupBlockingQueue[string, refreshTask](0, refreshTaskKey)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, q.Enqueue(ctx, refreshTask{key: "a", version: 1}))
+	assert.True(t, q.Contains("a"))
+	task, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, refreshTask{key: "a", version: 1}, task)
+	// 出队之后 key 被释放了
+	assert.False(t, q.Contains("a"))
+	require.NoError(t, q.Enqueue(ctx, refreshTask{key: "a", version: 2}))
+	task, err = q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, refreshTask{key: "a", version: 2}, task)
+
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		assert.NoError(t, q.Enqueue(ctx, refreshTask{key: "b"}))
+	}()
+	task, err = q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "b", task.key)
+}
+
+func TestDedupBlockingQueue_Close(t *testing.T) {
+	q := NewDedupBlockingQueue[string, refreshTask](0, refreshTaskKey)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, q.Enqueue(ctx, refreshTask{key: "a"}))
+	require.NoError(t, q.Close())
+	// 即便是重复的元素，关闭之后也不能入队
+	assert.Equal(t, ErrQueueClosed, q.Enqueue(ctx, refreshTask{key: "a"}))
+	assert.Equal(t, ErrQueueClosed, q.Enqueue(ctx, refreshTask{key: "b"}))
+	task, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "a", task.key)
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, ErrQueueClosed, err)
+}
+
+type refreshTask struct {
+	key     string
+	version int
+}
+
+func refreshTaskKey(t refreshTask) string {
+	return t.key
+}
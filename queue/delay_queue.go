@@ -33,6 +33,8 @@ type DelayQueue[T Delayable] struct {
 	mutex         *sync.Mutex
 	dequeueSignal *cond
 	enqueueSignal *cond
+	// closed 为 true 表示队列已经关闭
+	closed bool
 }
 
 func NewDelayQueue[T Delayable](c int) *DelayQueue[T] {
@@ -65,6 +67,10 @@ func (d *DelayQueue[T]) Enqueue(ctx context.Context, t T) error {
 		default:
 		}
 		d.mutex.Lock()
+		if d.closed {
+			d.mutex.Unlock()
+			return ErrQueueClosed
+		}
 		err := d.q.Enqueue(t)
 		switch err {
 		case nil:
@@ -137,6 +143,12 @@ func (d *DelayQueue[T]) Dequeue(ctx context.Context) (T, error) {
 				// 进入下一个循环。这里可能是有新的元素入队，也可能是到期了
 			}
 		case queue.ErrEmptyQueue:
+			// 关闭之后，剩余元素都已经取完了
+			if d.closed {
+				d.mutex.Unlock()
+				var t T
+				return t, ErrQueueClosed
+			}
 			signal := d.enqueueSignal.signalCh()
 			select {
 			case <-ctx.Done():
@@ -152,6 +164,62 @@ func (d *DelayQueue[T]) Dequeue(ctx context.Context) (T, error) {
 	}
 }
 
+// Peek 返回队头元素，也就是最早到期的元素，但是并不会将其出队
+// 该方法不会阻塞，也不要求队头元素已经到期。队列为空的时候返回 ErrEmptyQueue
+func (d *DelayQueue[T]) Peek() (T, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.q.Peek()
+}
+
+// Len 返回队列中元素的个数，包括尚未到期的元素
+func (d *DelayQueue[T]) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.q.Len()
+}
+
+// Remove 删除所有满足 filter 的元素，并且返回被删除的元素
+// 例如订单支付成功之后，可以用这个方法取消该订单的超时任务
+func (d *DelayQueue[T]) Remove(filter func(t T) bool) []T {
+	d.mutex.Lock()
+	res := d.q.Remove(filter)
+	if len(res) == 0 {
+		d.mutex.Unlock()
+		return res
+	}
+	// 腾出了空位，唤醒阻塞在入队上的 goroutine
+	d.dequeueSignal.broadcast()
+	return res
+}
+
+// Update 用 t 替换第一个满足 filter 的元素，也就是重新设置该元素的到期时间
+// 如果没有满足条件的元素，返回 false
+func (d *DelayQueue[T]) Update(filter func(t T) bool, t T) bool {
+	d.mutex.Lock()
+	if !d.q.Update(filter, t) {
+		d.mutex.Unlock()
+		return false
+	}
+	// 队头可能发生了变化，唤醒出队的 goroutine 重新计算等待时间
+	d.enqueueSignal.broadcast()
+	return true
+}
+
+// Close 关闭队列，剩余的元素依旧要等到期之后才能出队
+func (d *DelayQueue[T]) Close() error {
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.enqueueSignal.broadcast()
+	d.mutex.Lock()
+	d.dequeueSignal.broadcast()
+	return nil
+}
+
 type Delayable interface {
 	Delay() time.Duration
 }
@@ -292,6 +292,165 @@ func TestDelayQueue_Enqueue(t *testing.T) {
 	})
 }
 
+func TestDelayQueue_Peek(t *testing.T) {
+	t.Parallel()
+	q := NewDelayQueue[delayElem](3)
+	_, err := q.Peek()
+	assert.Equal(t, ErrEmptyQueue, err)
+	assert.Equal(t, 0, q.Len())
+
+	now := time.Now()
+	q = newDelayQueue(t, delayElem{
+		deadline: now.Add(time.Minute),
+		val:      2,
+	}, delayElem{
+		deadline: now.Add(time.Second),
+		val:      1,
+	})
+	ele, err := q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, 1, ele.val)
+	// Peek 不会出队
+	assert.Equal(t, 2, q.Len())
+}
+
+func TestDelayQueue_Remove(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	q := newDelayQueue(t, delayElem{
+		deadline: now.Add(time.Millisecond * 100),
+		val:      1,
+	}, delayElem{
+		deadline: now.Add(time.Millisecond * 200),
+		val:      2,
+	}, delayElem{
+		deadline: now.Add(time.Millisecond * 300),
+		val:      3,
+	})
+	removed := q.Remove(func(t delayElem) bool {
+		return t.val == 4
+	})
+	assert.Empty(t, removed)
+
+	removed = q.Remove(func(t delayElem) bool {
+		return t.val == 1
+	})
+	require.Len(t, removed, 1)
+	assert.Equal(t, 1, removed[0].val)
+	assert.Equal(t, 2, q.Len())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ele, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, ele.val)
+
+	// 删除元素之后，阻塞的入队可以成功
+	q = newDelayQueue(t, delayElem{
+		deadline: now.Add(time.Minute),
+		val:      1,
+	})
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		q.Remove(func(t delayElem) bool {
+			return t.val == 1
+		})
+	}()
+	err = q.Enqueue(ctx, delayElem{deadline: now, val: 2})
+	require.NoError(t, err)
+	ele, err = q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, ele.val)
+}
+
+func TestDelayQueue_Update(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	q := newDelayQueue(t, delayElem{
+		deadline: now.Add(time.Minute),
+		val:      1,
+	}, delayElem{
+		deadline: now.Add(time.Minute * 2),
+		val:      2,
+	})
+	ok := q.Update(func(t delayElem) bool {
+		return t.val == 3
+	}, delayElem{deadline: now, val: 3})
+	assert.False(t, ok)
+
+	// 出队的 goroutine 已经在等待一分钟之后的队头，
+	// 重新调度之后它应该被唤醒，拿到提前到期的元素
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		q.Update(func(t delayElem) bool {
+			return t.val == 2
+		}, delayElem{deadline: time.Now().Add(time.Millisecond * 100), val: 2})
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ele, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, ele.val)
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestDelayQueue_Close(t *testing.T) {
+	t.Parallel()
+	t.Run("enqueue after close", func(t *testing.T) {
+		q := NewDelayQueue[delayElem](3)
+		require.NoError(t, q.Close())
+		// 重复关闭没有影响
+		require.NoError(t, q.Close())
+		err := q.Enqueue(context.Background(), delayElem{val: 123, deadline: time.Now()})
+		assert.Equal(t, ErrQueueClosed, err)
+	})
+
+	// 关闭之后，剩余元素到期了依旧可以取出来
+	t.Run("drain after close", func(t *testing.T) {
+		now := time.Now()
+		q := newDelayQueue(t, delayElem{
+			val:      123,
+			deadline: now.Add(time.Millisecond * 100),
+		})
+		require.NoError(t, q.Close())
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ele, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 123, ele.val)
+		assert.True(t, time.Since(now) >= time.Millisecond*100)
+		_, err = q.Dequeue(ctx)
+		assert.Equal(t, ErrQueueClosed, err)
+	})
+
+	t.Run("close while dequeue blocking", func(t *testing.T) {
+		q := NewDelayQueue[delayElem](3)
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			_ = q.Close()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := q.Dequeue(ctx)
+		assert.Equal(t, ErrQueueClosed, err)
+	})
+
+	t.Run("close while enqueue blocking", func(t *testing.T) {
+		q := newDelayQueue(t, delayElem{
+			val:      123,
+			deadline: time.Now().Add(time.Minute),
+		})
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			_ = q.Close()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := q.Enqueue(ctx, delayElem{val: 234, deadline: time.Now()})
+		assert.Equal(t, ErrQueueClosed, err)
+	})
+}
+
 func newDelayQueue(t *testing.T, eles ...delayElem) *DelayQueue[delayElem] {
 	q := NewDelayQueue[delayElem](len(eles))
 	for _, ele := range eles {
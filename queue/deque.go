@@ -0,0 +1,178 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import "github.com/ecodeclub/ekit/internal/errs"
+
+// 默认的初始容量
+const defaultDequeCapacity = 16
+
+// Deque 基于环形数组的双端队列
+// 在队头和队尾入队、出队的时间复杂度都是 O(1)
+// 容量不足的时候会自动扩容，元素比较少的时候会自动缩容
+// 注意，Deque 不是线程安全的，并发场景请使用 ConcurrentDeque 或者 ConcurrentBlockingDeque
+type Deque[T any] struct {
+	data []T
+	// 队头元素下标
+	head int
+	// 包含多少个元素
+	count int
+
+	// zero 不能作为返回值返回，防止用户篡改
+	zero T
+}
+
+// NewDeque 创建一个双端队列，capacity 是初始容量
+// capacity <= 0 的时候使用默认的初始容量
+func NewDeque[T any](capacity int) *Deque[T] {
+	if capacity <= 0 {
+		capacity = defaultDequeCapacity
+	}
+	return &Deque[T]{
+		data: make([]T, capacity),
+	}
+}
+
+// PushFront 在队头放入元素
+func (d *Deque[T]) PushFront(t T) {
+	d.growIfNecessary()
+	d.head = d.index(-1)
+	d.data[d.head] = t
+	d.count++
+}
+
+// PushBack 在队尾放入元素
+func (d *Deque[T]) PushBack(t T) {
+	d.growIfNecessary()
+	d.data[d.index(d.count)] = t
+	d.count++
+}
+
+// PopFront 从队头取出元素，队列为空的时候返回 ErrEmptyQueue
+func (d *Deque[T]) PopFront() (T, error) {
+	if d.count == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	res := d.data[d.head]
+	// 为了释放内存，GC
+	d.data[d.head] = d.zero
+	d.head = d.index(1)
+	d.count--
+	d.shrinkIfNecessary()
+	return res, nil
+}
+
+// PopBack 从队尾取出元素，队列为空的时候返回 ErrEmptyQueue
+func (d *Deque[T]) PopBack() (T, error) {
+	if d.count == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	tail := d.index(d.count - 1)
+	res := d.data[tail]
+	// 为了释放内存，GC
+	d.data[tail] = d.zero
+	d.count--
+	d.shrinkIfNecessary()
+	return res, nil
+}
+
+// PeekFront 返回队头元素，但是不会将其出队
+func (d *Deque[T]) PeekFront() (T, error) {
+	if d.count == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return d.data[d.head], nil
+}
+
+// PeekBack 返回队尾元素，但是不会将其出队
+func (d *Deque[T]) PeekBack() (T, error) {
+	if d.count == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return d.data[d.index(d.count-1)], nil
+}
+
+// Get 返回相对于队头的第 index 个元素，队头是第 0 个
+// 在下标超出范围的情况下，返回错误
+func (d *Deque[T]) Get(index int) (T, error) {
+	if index < 0 || index >= d.count {
+		var t T
+		return t, errs.NewErrIndexOutOfRange(d.count, index)
+	}
+	return d.data[d.index(index)], nil
+}
+
+func (d *Deque[T]) Len() int {
+	return d.count
+}
+
+// Cap 返回当前底层数组的容量
+func (d *Deque[T]) Cap() int {
+	return len(d.data)
+}
+
+// AsSlice 按照从队头到队尾的顺序返回所有元素
+func (d *Deque[T]) AsSlice() []T {
+	res := make([]T, d.count)
+	d.copyTo(res)
+	return res
+}
+
+// index 计算相对于队头偏移 offset 之后在底层数组中的下标
+func (d *Deque[T]) index(offset int) int {
+	n := len(d.data)
+	return ((d.head+offset)%n + n) % n
+}
+
+func (d *Deque[T]) copyTo(dst []T) {
+	if d.head+d.count <= len(d.data) {
+		copy(dst, d.data[d.head:d.head+d.count])
+		return
+	}
+	n := copy(dst, d.data[d.head:])
+	copy(dst[n:], d.data[:d.count-n])
+}
+
+func (d *Deque[T]) growIfNecessary() {
+	if d.count < len(d.data) {
+		return
+	}
+	newCap := len(d.data) * 2
+	if newCap == 0 {
+		newCap = defaultDequeCapacity
+	}
+	d.resize(newCap)
+}
+
+// shrinkIfNecessary 元素不足容量的 1/4 的时候缩容一半
+// 容量比较小的时候不缩容，避免频繁扩缩容
+func (d *Deque[T]) shrinkIfNecessary() {
+	c := len(d.data)
+	if c <= 64 || d.count > c/4 {
+		return
+	}
+	d.resize(c / 2)
+}
+
+func (d *Deque[T]) resize(newCap int) {
+	data := make([]T, newCap)
+	d.copyTo(data)
+	d.data = data
+	d.head = 0
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ecodeclub/ekit/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeque_Push(t *testing.T) {
+	testCases := []struct {
+		name      string
+		d         func() *Deque[int]
+		wantSlice []int
+		wantCap   int
+	}{
+		{
+			name: "push back",
+			d: func() *Deque[int] {
+				d := NewDeque[int](4)
+				d.PushBack(1)
+				d.PushBack(2)
+				d.PushBack(3)
+				return d
+			},
+			wantSlice: []int{1, 2, 3},
+			wantCap:   4,
+		},
+		{
+			name: "push front",
+			d: func() *Deque[int] {
+				d := NewDeque[int](4)
+				d.PushFront(1)
+				d.PushFront(2)
+				d.PushFront(3)
+				return d
+			},
+			wantSlice: []int{3, 2, 1},
+			wantCap:   4,
+		},
+		{
+			// 队头绕回到了数组末尾，然后扩容
+			name: "wrap around and grow",
+			d: func() *Deque[int] {
+				d := NewDeque[int](4)
+				d.PushBack(3)
+				d.PushBack(4)
+				d.PushFront(2)
+				d.PushFront(1)
+				d.PushBack(5)
+				d.PushFront(0)
+				return d
+			},
+			wantSlice: []int{0, 1, 2, 3, 4, 5},
+			wantCap:   8,
+		},
+		{
+			name: "default capacity",
+			d: func() *Deque[int] {
+				d := NewDeque[int](0)
+				d.PushBack(1)
+				return d
+			},
+			wantSlice: []int{1},
+			wantCap:   defaultDequeCapacity,
+		},
+		{
+			name: "zero value",
+			d: func() *Deque[int] {
+				d := &Deque[int]{}
+				d.PushFront(1)
+				d.PushBack(2)
+				return d
+			},
+			wantSlice: []int{1, 2},
+			wantCap:   defaultDequeCapacity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := tc.d()
+			assert.Equal(t, tc.wantSlice, d.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), d.Len())
+			assert.Equal(t, tc.wantCap, d.Cap())
+		})
+	}
+}
+
+func TestDeque_Pop(t *testing.T) {
+	d := NewDeque[int](4)
+	_, err := d.PopFront()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = d.PopBack()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = d.PeekFront()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = d.PeekBack()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	for i := 0; i < 6; i++ {
+		d.PushBack(i)
+	}
+	val, err := d.PeekFront()
+	require.NoError(t, err)
+	assert.Equal(t, 0, val)
+	val, err = d.PeekBack()
+	require.NoError(t, err)
+	assert.Equal(t, 5, val)
+
+	val, err = d.PopFront()
+	require.NoError(t, err)
+	assert.Equal(t, 0, val)
+	val, err = d.PopBack()
+	require.NoError(t, err)
+	assert.Equal(t, 5, val)
+	assert.Equal(t, []int{1, 2, 3, 4}, d.AsSlice())
+
+	// 当作栈使用
+	d.PushBack(6)
+	val, err = d.PopBack()
+	require.NoError(t, err)
+	assert.Equal(t, 6, val)
+	// 弹出的位置要置为零值
+	assert.Equal(t, 0, d.data[d.index(d.Len())])
+}
+
+func TestDeque_Get(t *testing.T) {
+	d := NewDeque[int](4)
+	d.PushBack(2)
+	d.PushBack(3)
+	d.PushFront(1)
+	testCases := []struct {
+		name    string
+		index   int
+		wantVal int
+		wantErr error
+	}{
+		{
+			name:    "first",
+			index:   0,
+			wantVal: 1,
+		},
+		{
+			name:    "last",
+			index:   2,
+			wantVal: 3,
+		},
+		{
+			name:    "negative index",
+			index:   -1,
+			wantErr: errs.NewErrIndexOutOfRange(3, -1),
+		},
+		{
+			name:    "index out of range",
+			index:   3,
+			wantErr: errs.NewErrIndexOutOfRange(3, 3),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			val, err := d.Get(tc.index)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val)
+		})
+	}
+}
+
+func TestDeque_Shrink(t *testing.T) {
+	d := NewDeque[int](0)
+	for i := 0; i < 1024; i++ {
+		d.PushFront(i)
+	}
+	assert.Equal(t, 1024, d.Cap())
+	for i := 0; i < 1000; i++ {
+		_, err := d.PopBack()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 64, d.Cap())
+	want := make([]int, 0, 24)
+	for i := 1023; i >= 1000; i-- {
+		want = append(want, i)
+	}
+	assert.Equal(t, want, d.AsSlice())
+}
+
+func ExampleDeque() {
+	d := NewDeque[int](0)
+	d.PushBack(2)
+	d.PushBack(3)
+	d.PushFront(1)
+	front, _ := d.PopFront()
+	back, _ := d.PopBack()
+	fmt.Println(front, back, d.AsSlice())
+	// Output:
+	// 1 3 [2]
+}
@@ -14,7 +14,20 @@
 
 package queue
 
-import "github.com/ecodeclub/ekit/internal/queue"
+import (
+	"errors"
 
-// ErrOutOfCapacity 超过容量
-var ErrOutOfCapacity = queue.ErrOutOfCapacity
+	"github.com/ecodeclub/ekit/internal/queue"
+)
+
+var (
+	// ErrOutOfCapacity 超过容量
+	ErrOutOfCapacity = queue.ErrOutOfCapacity
+	// ErrEmptyQueue 队列为空
+	ErrEmptyQueue = queue.ErrEmptyQueue
+	// ErrQueueClosed 队列已经关闭
+	// 关闭之后入队总是返回该错误；出队会先把剩余元素取完，之后再返回该错误
+	ErrQueueClosed = errors.New("ekit: 队列已关闭")
+	// ErrInvalidHandle 句柄对应的元素已经不在队列中，或者句柄不属于这个队列
+	ErrInvalidHandle = errors.New("ekit: 无效的句柄")
+)
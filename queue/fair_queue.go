@@ -0,0 +1,368 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ecodeclub/ekit/bean/option"
+)
+
+var _ BlockingQueue[any] = &FairQueue[int, any]{}
+
+// FairQueue 多租户公平阻塞队列
+// 每个租户都有自己的子队列，出队的时候按照权重在租户之间轮转，
+// 避免某一个租户的大量元素饿死其它租户。
+// 默认情况下每个元素的开销都是 1，也就是加权轮询：一轮之中，权重为 w 的租户最多出队 w 个元素；
+// 通过 FairQueueWithCost 指定元素的开销之后就是差额轮询（Deficit Round Robin）：
+// 一轮之中，权重为 w 的租户出队的元素的总开销大约是 w * quantum。
+// 同一个租户内部的元素遵循 FIFO
+type FairQueue[K comparable, T any] struct {
+	keyFunc func(t T) K
+	// cost 计算元素的开销，必须返回正数
+	cost          func(t T) int
+	quantum       int
+	defaultWeight int
+	weights       map[K]int
+	// tenantCapacity 每个租户的容量，小于等于 0 的时候是无界的
+	tenantCapacity int
+
+	mutex    *sync.RWMutex
+	notEmpty *cond
+	notFull  *cond
+
+	// tenants 有元素的租户，以及单独指定过权重的租户
+	// 使用默认权重的租户在元素取完之后就会被移除
+	tenants map[K]*fairTenant[K, T]
+	// active 有元素的租户，按照轮转的顺序排列
+	active []*fairTenant[K, T]
+	// cur 当前轮到的租户在 active 中的下标
+	cur int
+	// count 所有租户的元素总数
+	count  int
+	closed bool
+}
+
+// TenantStats 租户的统计信息
+type TenantStats struct {
+	// Len 租户当前还有多少个元素
+	Len int
+	// Enqueued 累计入队的元素数量
+	Enqueued uint64
+	// Dequeued 累计出队的元素数量
+	Dequeued uint64
+	Weight   int
+}
+
+type fairTenant[K comparable, T any] struct {
+	key    K
+	items  *Deque[T]
+	weight int
+	// deficit 当前剩余的额度
+	deficit int
+	// inTurn 是否已经为这一轮补充了额度
+	inTurn bool
+	stats  TenantStats
+}
+
+// NewFairQueue 创建一个多租户公平队列，keyFunc 用于计算元素所属的租户
+func NewFairQueue[K comparable, T any](keyFunc func(t T) K, opts ...option.Option[FairQueue[K, T]]) *FairQueue[K, T] {
+	mutex := &sync.RWMutex{}
+	res := &FairQueue[K, T]{
+		keyFunc: keyFunc,
+		cost: func(t T) int {
+			return 1
+		},
+		quantum:       1,
+		defaultWeight: 1,
+		weights:       make(map[K]int),
+		mutex:         mutex,
+		notEmpty:      newCond(mutex),
+		notFull:       newCond(mutex),
+		tenants:       make(map[K]*fairTenant[K, T]),
+	}
+	option.Apply(res, opts...)
+	return res
+}
+
+// FairQueueWithWeights 指定租户的权重，没有指定的租户使用默认权重
+// 权重必须是正数
+func FairQueueWithWeights[K comparable, T any](weights map[K]int) option.Option[FairQueue[K, T]] {
+	return func(q *FairQueue[K, T]) {
+		for k, w := range weights {
+			q.weights[k] = w
+		}
+	}
+}
+
+// FairQueueWithDefaultWeight 指定默认权重，默认是 1
+func FairQueueWithDefaultWeight[K comparable, T any](weight int) option.Option[FairQueue[K, T]] {
+	return func(q *FairQueue[K, T]) {
+		q.defaultWeight = weight
+	}
+}
+
+// FairQueueWithTenantCapacity 指定每个租户的容量，某个租户满了之后，只有该租户的入队会阻塞
+func FairQueueWithTenantCapacity[K comparable, T any](capacity int) option.Option[FairQueue[K, T]] {
+	return func(q *FairQueue[K, T]) {
+		q.tenantCapacity = capacity
+	}
+}
+
+// FairQueueWithCost 使用差额轮询，cost 计算元素的开销，必须返回正数，否则 Enqueue 会返回错误
+// 每一轮权重为 w 的租户会获得 w * quantum 的额度
+func FairQueueWithCost[K comparable, T any](cost func(t T) int, quantum int) option.Option[FairQueue[K, T]] {
+	return func(q *FairQueue[K, T]) {
+		q.cost = cost
+		q.quantum = quantum
+	}
+}
+
+// Enqueue 将元素放入所属租户的子队列
+// 如果该租户的子队列已经满了，那么会阻塞直到有空位、ctx 结束或者队列被关闭
+func (f *FairQueue[K, T]) Enqueue(ctx context.Context, t T) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if cost := f.cost(t); cost <= 0 {
+		return fmt.Errorf("ekit: 元素的开销必须是正数，实际值 %d", cost)
+	}
+	key := f.keyFunc(t)
+	f.mutex.Lock()
+	for !f.closed && f.isFull(key) {
+		signal := f.notFull.signalCh()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+			// 收到信号要重新加锁
+			f.mutex.Lock()
+		}
+	}
+	if f.closed {
+		f.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	// 确定能够入队之后才创建租户，避免失败的入队留下空的租户
+	tenant := f.tenant(key)
+	if tenant.items.Len() == 0 {
+		// 新加入的租户排在最后
+		f.active = append(f.active, tenant)
+	}
+	tenant.items.PushBack(t)
+	tenant.stats.Enqueued++
+	f.count++
+	// 这里会释放锁
+	f.notEmpty.broadcast()
+	return nil
+}
+
+// Dequeue 按照权重从各个租户中取出元素
+// 所有租户都没有元素的时候会阻塞
+func (f *FairQueue[K, T]) Dequeue(ctx context.Context) (T, error) {
+	var res T
+	if ctx.Err() != nil {
+		return res, ctx.Err()
+	}
+	f.mutex.Lock()
+	for !f.closed && f.count == 0 {
+		signal := f.notEmpty.signalCh()
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-signal:
+			f.mutex.Lock()
+		}
+	}
+	// 关闭之后，依旧允许把剩余的元素取完
+	if f.count == 0 {
+		f.mutex.Unlock()
+		return res, ErrQueueClosed
+	}
+	res = f.next()
+	f.notFull.broadcast()
+	return res, nil
+}
+
+// SetWeight 修改租户的权重，从该租户的下一轮开始生效
+func (f *FairQueue[K, T]) SetWeight(key K, weight int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.weights[key] = weight
+	if tenant, ok := f.tenants[key]; ok {
+		tenant.weight = weight
+	}
+}
+
+// Len 返回所有租户的元素总数
+func (f *FairQueue[K, T]) Len() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.count
+}
+
+// Stats 返回租户的统计信息
+// 使用默认权重的租户在元素取完之后就会被移除，累计的统计信息也会被清空，这时候返回 false
+func (f *FairQueue[K, T]) Stats(key K) (TenantStats, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	tenant, ok := f.tenants[key]
+	if !ok {
+		return TenantStats{}, false
+	}
+	return tenant.snapshot(), true
+}
+
+// AllStats 返回所有租户的统计信息
+func (f *FairQueue[K, T]) AllStats() map[K]TenantStats {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	res := make(map[K]TenantStats, len(f.tenants))
+	for k, tenant := range f.tenants {
+		res[k] = tenant.snapshot()
+	}
+	return res
+}
+
+// Close 关闭队列
+// 关闭之后 Enqueue 总是返回 ErrQueueClosed，
+// Dequeue 会继续按照权重返回剩余的元素，取完之后返回 ErrQueueClosed。
+// 所有阻塞的 goroutine 都会被唤醒。
+// 重复调用 Close 不会有任何效果
+func (f *FairQueue[K, T]) Close() error {
+	f.mutex.Lock()
+	if f.closed {
+		f.mutex.Unlock()
+		return nil
+	}
+	f.closed = true
+	f.notEmpty.broadcast()
+	f.mutex.Lock()
+	f.notFull.broadcast()
+	return nil
+}
+
+// isFull 判断租户的子队列是否已经满了，必须在锁范围内调用
+func (f *FairQueue[K, T]) isFull(key K) bool {
+	if f.tenantCapacity <= 0 {
+		return false
+	}
+	tenant, ok := f.tenants[key]
+	return ok && tenant.items.Len() >= f.tenantCapacity
+}
+
+// tenant 返回租户，不存在的时候创建一个，必须在锁范围内调用
+func (f *FairQueue[K, T]) tenant(key K) *fairTenant[K, T] {
+	tenant, ok := f.tenants[key]
+	if ok {
+		return tenant
+	}
+	weight, ok := f.weights[key]
+	if !ok {
+		weight = f.defaultWeight
+	}
+	tenant = &fairTenant[K, T]{
+		key:    key,
+		items:  NewDeque[T](0),
+		weight: weight,
+	}
+	f.tenants[key] = tenant
+	return tenant
+}
+
+// next 按照差额轮询取出下一个元素，调用者必须确保队列不为空
+func (f *FairQueue[K, T]) next() T {
+	// skipped 连续有多少个租户因为额度不够被跳过
+	skipped := 0
+	for {
+		if f.cur >= len(f.active) {
+			f.cur = 0
+		}
+		if skipped == len(f.active) {
+			// 整整一轮都没有租户能够出队，直接跳过这些空转的轮次
+			f.fastForward()
+			skipped = 0
+		}
+		tenant := f.active[f.cur]
+		if !tenant.inTurn {
+			tenant.deficit += f.quota(tenant)
+			tenant.inTurn = true
+		}
+		head, _ := tenant.items.PeekFront()
+		cost := f.cost(head)
+		if cost > tenant.deficit {
+			// 额度不够了，轮到下一个租户，剩余的额度留到下一轮
+			tenant.inTurn = false
+			f.cur++
+			skipped++
+			continue
+		}
+		tenant.deficit -= cost
+		res, _ := tenant.items.PopFront()
+		tenant.stats.Dequeued++
+		f.count--
+		if tenant.items.Len() == 0 {
+			// 租户没有元素了，额度清零，避免积攒额度
+			tenant.deficit = 0
+			tenant.inTurn = false
+			f.active = append(f.active[:f.cur], f.active[f.cur+1:]...)
+			if _, ok := f.weights[tenant.key]; !ok {
+				delete(f.tenants, tenant.key)
+			}
+		}
+		return res
+	}
+}
+
+// fastForward 计算至少还要多少轮才会有租户的额度足够，一次性补上这些轮次的额度
+// 这样即使元素的开销远大于 quantum，也不会在锁里面空转很多轮
+// 调用的时候所有租户的额度都不够出队，并且都还没有补充下一轮的额度
+func (f *FairQueue[K, T]) fastForward() {
+	rounds := -1
+	for _, tenant := range f.active {
+		head, _ := tenant.items.PeekFront()
+		quota := f.quota(tenant)
+		// 下一轮本来就会补充一次额度，所以这里少算一轮
+		r := (f.cost(head)-tenant.deficit+quota-1)/quota - 1
+		if rounds < 0 || r < rounds {
+			rounds = r
+		}
+	}
+	if rounds <= 0 {
+		return
+	}
+	for _, tenant := range f.active {
+		tenant.deficit += rounds * f.quota(tenant)
+	}
+}
+
+// quota 租户每一轮获得的额度
+func (f *FairQueue[K, T]) quota(tenant *fairTenant[K, T]) int {
+	res := tenant.weight * f.quantum
+	// 权重或者 quantum 不合法的时候至少给 1，避免死循环
+	if res < 1 {
+		res = 1
+	}
+	return res
+}
+
+func (t *fairTenant[K, T]) snapshot() TenantStats {
+	res := t.stats
+	res.Len = t.items.Len()
+	res.Weight = t.weight
+	return res
+}
@@ -0,0 +1,284 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/bean/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFairQueue_WeightedRoundRobin(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     []option.Option[FairQueue[string, fairTask]]
+		tasks    []fairTask
+		wantKeys string
+	}{
+		{
+			name: "same weight",
+			tasks: fairTasks(
+				"a", "a", "a", "a",
+				"b", "b"),
+			wantKeys: "ababaa",
+		},
+		{
+			name: "weighted",
+			opts: []option.Option[FairQueue[string, fairTask]]{
+				FairQueueWithWeights[string, fairTask](map[string]int{"a": 3}),
+			},
+			tasks: fairTasks(
+				"a", "a", "a", "a", "a", "a",
+				"b", "b", "b",
+				"c"),
+			wantKeys: "aaabcaaabb",
+		},
+		{
+			name: "default weight",
+			opts: []option.Option[FairQueue[string, fairTask]]{
+				FairQueueWithDefaultWeight[string, fairTask](2),
+				FairQueueWithWeights[string, fairTask](map[string]int{"b": 1}),
+			},
+			tasks: fairTasks(
+				"a", "a", "a", "a",
+				"b", "b", "b"),
+			wantKeys: "aabaabb",
+		},
+		{
+			// 差额轮询，a 的开销是 4，b 的开销是 1，每一轮的额度是 5
+			name: "deficit round robin",
+			opts: []option.Option[FairQueue[string, fairTask]]{
+				FairQueueWithCost[string, fairTask](func(t fairTask) int {
+					if t.tenant == "a" {
+						return 4
+					}
+					return 1
+				}, 5),
+			},
+			tasks: fairTasks(
+				"a", "a", "a",
+				"b", "b", "b", "b", "b", "b", "b"),
+			wantKeys: "abbbbbabba",
+		},
+		{
+			// 开销远大于额度的时候，一次性补充额度，而不是一轮一轮地空转
+			name: "large cost",
+			opts: []option.Option[FairQueue[string, fairTask]]{
+				FairQueueWithCost[string, fairTask](func(t fairTask) int {
+					if t.tenant == "a" {
+						return 1 << 40
+					}
+					return 1
+				}, 1),
+			},
+			tasks: fairTasks(
+				"a", "a",
+				"b", "b"),
+			wantKeys: "bbaa",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewFairQueue[string, fairTask](fairTaskKey, tc.opts...)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			for _, task := range tc.tasks {
+				require.NoError(t, q.Enqueue(ctx, task))
+			}
+			assert.Equal(t, len(tc.tasks), q.Len())
+			keys := make([]byte, 0, len(tc.tasks))
+			last := make(map[string]int)
+			for q.Len() > 0 {
+				task, err := q.Dequeue(ctx)
+				require.NoError(t, err)
+				keys = append(keys, task.tenant...)
+				// 同一个租户内部是 FIFO
+				assert.Greater(t, task.id, last[task.tenant])
+				last[task.tenant] = task.id
+			}
+			assert.Equal(t, tc.wantKeys, string(keys))
+		})
+	}
+}
+
+func TestFairQueue_Enqueue(t *testing.T) {
+	t.Run("invalid context", func(t *testing.T) {
+		q := NewFairQueue[string, fairTask](fairTaskKey)
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		err := q.Enqueue(ctx, fairTask{tenant: "a"})
+		assert.Equal(t, context.DeadlineExceeded, err)
+		_, err = q.Dequeue(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	// 一个租户满了，不影响其它租户
+	t.Run("tenant capacity", func(t *testing.T) {
+		q := NewFairQueue[string, fairTask](fairTaskKey,
+			FairQueueWithTenantCapacity[string, fairTask](1))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, fairTask{tenant: "a", id: 1}))
+		require.NoError(t, q.Enqueue(ctx, fairTask{tenant: "b", id: 1}))
+		err := q.Enqueue(ctx, fairTask{tenant: "a", id: 2})
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("invalid cost", func(t *testing.T) {
+		q := NewFairQueue[string, fairTask](fairTaskKey,
+			FairQueueWithCost[string, fairTask](func(t fairTask) int {
+				return t.id
+			}, 1))
+		err := q.Enqueue(context.Background(), fairTask{tenant: "a", id: 0})
+		assert.EqualError(t, err, "ekit: 元素的开销必须是正数，实际值 0")
+		assert.Equal(t, 0, q.Len())
+		assert.Empty(t, q.AllStats())
+	})
+
+	t.Run("enqueue blocking and dequeue", func(t *testing.T) {
+		q := NewFairQueue[string, fairTask](fairTaskKey,
+			FairQueueWithTenantCapacity[string, fairTask](1))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, fairTask{tenant: "a", id: 1}))
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			_, err := q.Dequeue(ctx)
+			assert.NoError(t, err)
+		}()
+		require.NoError(t, q.Enqueue(ctx, fairTask{tenant: "a", id: 2}))
+	})
+}
+
+func TestFairQueue_Dequeue(t *testing.T) {
+	q := NewFairQueue[string, fairTask](fairTaskKey)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		assert.NoError(t, q.Enqueue(ctx, fairTask{tenant: "a", id: 1}))
+	}()
+	task, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, fairTask{tenant: "a", id: 1}, task)
+
+	timeoutCtx, timeoutCancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer timeoutCancel()
+	_, err = q.Dequeue(timeoutCtx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestFairQueue_Stats(t *testing.T) {
+	q := NewFairQueue[string, fairTask](fairTaskKey,
+		FairQueueWithWeights[string, fairTask](map[string]int{"a": 2}))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, ok := q.Stats("a")
+	assert.False(t, ok)
+	for _, task := range fairTasks("a", "a", "a", "b") {
+		require.NoError(t, q.Enqueue(ctx, task))
+	}
+	_, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	stats, ok := q.Stats("a")
+	assert.True(t, ok)
+	assert.Equal(t, TenantStats{Len: 2, Enqueued: 3, Dequeued: 1, Weight: 2}, stats)
+
+	q.SetWeight("b", 5)
+	q.SetWeight("c", 3)
+	assert.Equal(t, map[string]TenantStats{
+		"a": {Len: 2, Enqueued: 3, Dequeued: 1, Weight: 2},
+		"b": {Len: 1, Enqueued: 1, Weight: 5},
+	}, q.AllStats())
+	require.NoError(t, q.Enqueue(ctx, fairTask{tenant: "c", id: 1}))
+	stats, ok = q.Stats("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, stats.Weight)
+
+	// 使用默认权重的租户，取完之后就会被移除
+	require.NoError(t, q.Enqueue(ctx, fairTask{tenant: "d", id: 1}))
+	for q.Len() > 0 {
+		_, err = q.Dequeue(ctx)
+		require.NoError(t, err)
+	}
+	_, ok = q.Stats("d")
+	assert.False(t, ok)
+	assert.Len(t, q.AllStats(), 3)
+
+	// 入队失败不会留下租户
+	require.NoError(t, q.Close())
+	assert.Equal(t, ErrQueueClosed, q.Enqueue(ctx, fairTask{tenant: "e", id: 1}))
+	_, ok = q.Stats("e")
+	assert.False(t, ok)
+}
+
+func TestFairQueue_Close(t *testing.T) {
+	q := NewFairQueue[string, fairTask](fairTaskKey)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, task := range fairTasks("a", "a", "b") {
+		require.NoError(t, q.Enqueue(ctx, task))
+	}
+	require.NoError(t, q.Close())
+	require.NoError(t, q.Close())
+	assert.Equal(t, ErrQueueClosed, q.Enqueue(ctx, fairTask{tenant: "a"}))
+	keys := ""
+	for i := 0; i < 3; i++ {
+		task, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		keys += task.tenant
+	}
+	assert.Equal(t, "aba", keys)
+	_, err := q.Dequeue(ctx)
+	assert.Equal(t, ErrQueueClosed, err)
+
+	// 阻塞的 goroutine 会被唤醒
+	q = NewFairQueue[string, fairTask](fairTaskKey)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := q.Dequeue(ctx)
+		assert.Equal(t, ErrQueueClosed, err)
+	}()
+	time.Sleep(time.Millisecond * 100)
+	require.NoError(t, q.Close())
+	wg.Wait()
+}
+
+type fairTask struct {
+	tenant string
+	id     int
+}
+
+func fairTaskKey(t fairTask) string {
+	return t.tenant
+}
+
+// fairTasks 按照租户生成任务，同一个租户的任务 id 从 1 开始递增
+func fairTasks(tenants ...string) []fairTask {
+	ids := make(map[string]int)
+	res := make([]fairTask, 0, len(tenants))
+	for _, tenant := range tenants {
+		ids[tenant]++
+		res = append(res, fairTask{tenant: tenant, id: ids[tenant]})
+	}
+	return res
+}
@@ -0,0 +1,186 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import "github.com/ecodeclub/ekit"
+
+// Handle 是元素在 IndexedPriorityQueue 中的句柄
+// 入队的时候返回，后续可以通过它来调整或者删除该元素
+type Handle[T any] struct {
+	q     *IndexedPriorityQueue[T]
+	val   T
+	index int
+}
+
+// Value 返回句柄对应的元素
+func (h *Handle[T]) Value() T {
+	return h.val
+}
+
+// IndexedPriorityQueue 是一个支持修改优先级和删除任意元素的优先队列
+// 它基于小顶堆实现，每个元素都记录了自己在堆中的下标，
+// 所以 Fix、Update 和 Remove 的时间复杂度都是 O(log n)，Contains 是 O(1)
+// 当capacity <= 0时，为无界队列，否则为有界队列
+// 注意，IndexedPriorityQueue 不是线程安全的
+type IndexedPriorityQueue[T any] struct {
+	compare  ekit.Comparator[T]
+	capacity int
+	data     []*Handle[T]
+}
+
+// NewIndexedPriorityQueue 创建优先队列 capacity <= 0 时，为无界队列，否则有有界队列
+func NewIndexedPriorityQueue[T any](capacity int, compare ekit.Comparator[T]) *IndexedPriorityQueue[T] {
+	sliceCap := capacity
+	if capacity < 1 {
+		capacity = 0
+		sliceCap = 64
+	}
+	return &IndexedPriorityQueue[T]{
+		compare:  compare,
+		capacity: capacity,
+		data:     make([]*Handle[T], 0, sliceCap),
+	}
+}
+
+func (p *IndexedPriorityQueue[T]) Len() int {
+	return len(p.data)
+}
+
+// Cap 无界队列返回0，有界队列返回创建队列时设置的值
+func (p *IndexedPriorityQueue[T]) Cap() int {
+	return p.capacity
+}
+
+// Enqueue 入队，并且返回元素的句柄
+func (p *IndexedPriorityQueue[T]) Enqueue(t T) (*Handle[T], error) {
+	if p.capacity > 0 && len(p.data) == p.capacity {
+		return nil, ErrOutOfCapacity
+	}
+	h := &Handle[T]{q: p, val: t, index: len(p.data)}
+	p.data = append(p.data, h)
+	p.up(h.index)
+	return h, nil
+}
+
+// Dequeue 取出优先级最高，也就是最小的元素
+func (p *IndexedPriorityQueue[T]) Dequeue() (T, error) {
+	if len(p.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return p.remove(0), nil
+}
+
+// Peek 返回优先级最高的元素，但是不会将其出队
+func (p *IndexedPriorityQueue[T]) Peek() (T, error) {
+	if len(p.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return p.data[0].val, nil
+}
+
+// Contains 判断句柄对应的元素是否还在队列中
+func (p *IndexedPriorityQueue[T]) Contains(h *Handle[T]) bool {
+	return h != nil && h.q == p && h.index >= 0 && h.index < len(p.data) && p.data[h.index] == h
+}
+
+// Fix 在元素的优先级发生变化之后，调整它在堆中的位置
+// 一般用于 T 是指针，并且直接修改了元素的场景，否则应该使用 Update
+func (p *IndexedPriorityQueue[T]) Fix(h *Handle[T]) error {
+	if !p.Contains(h) {
+		return ErrInvalidHandle
+	}
+	p.fix(h.index)
+	return nil
+}
+
+// Update 将句柄对应的元素替换为 t，并且调整它在堆中的位置
+func (p *IndexedPriorityQueue[T]) Update(h *Handle[T], t T) error {
+	if !p.Contains(h) {
+		return ErrInvalidHandle
+	}
+	h.val = t
+	p.fix(h.index)
+	return nil
+}
+
+// Remove 删除句柄对应的元素，并且返回该元素
+func (p *IndexedPriorityQueue[T]) Remove(h *Handle[T]) (T, error) {
+	if !p.Contains(h) {
+		var t T
+		return t, ErrInvalidHandle
+	}
+	return p.remove(h.index), nil
+}
+
+func (p *IndexedPriorityQueue[T]) remove(i int) T {
+	h := p.data[i]
+	last := len(p.data) - 1
+	if i != last {
+		p.swap(i, last)
+	}
+	p.data[last] = nil
+	p.data = p.data[:last]
+	h.index = -1
+	if i != last {
+		p.fix(i)
+	}
+	return h.val
+}
+
+func (p *IndexedPriorityQueue[T]) fix(i int) {
+	if !p.down(i) {
+		p.up(i)
+	}
+}
+
+func (p *IndexedPriorityQueue[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if p.compare(p.data[i].val, p.data[parent].val) >= 0 {
+			break
+		}
+		p.swap(i, parent)
+		i = parent
+	}
+}
+
+// down 下沉，如果元素的位置发生了变化，返回 true
+func (p *IndexedPriorityQueue[T]) down(i int) bool {
+	start := i
+	n := len(p.data)
+	for {
+		minPos := i
+		if left := i*2 + 1; left < n && p.compare(p.data[left].val, p.data[minPos].val) < 0 {
+			minPos = left
+		}
+		if right := i*2 + 2; right < n && p.compare(p.data[right].val, p.data[minPos].val) < 0 {
+			minPos = right
+		}
+		if minPos == i {
+			break
+		}
+		p.swap(i, minPos)
+		i = minPos
+	}
+	return i != start
+}
+
+func (p *IndexedPriorityQueue[T]) swap(i, j int) {
+	p.data[i], p.data[j] = p.data[j], p.data[i]
+	p.data[i].index = i
+	p.data[j].index = j
+}
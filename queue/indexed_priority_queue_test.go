@@ -0,0 +1,226 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexedPriorityQueue_EnqueueDequeue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		data     []int
+		wantErr  error
+		wantLen  int
+	}{
+		{
+			name:     "unbounded",
+			capacity: 0,
+			data:     []int{6, 5, 4, 3, 2, 1},
+			wantLen:  6,
+		},
+		{
+			name:     "bounded",
+			capacity: 6,
+			data:     []int{1, 6, 2, 5, 3, 4},
+			wantLen:  6,
+		},
+		{
+			name:     "out of capacity",
+			capacity: 3,
+			data:     []int{3, 2, 1, 0},
+			wantErr:  ErrOutOfCapacity,
+			wantLen:  3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewIndexedPriorityQueue[int](tc.capacity, compare())
+			assert.Equal(t, tc.capacity, q.Cap())
+			var err error
+			for _, d := range tc.data {
+				_, err = q.Enqueue(d)
+				if err != nil {
+					break
+				}
+			}
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLen, q.Len())
+			want := append([]int(nil), tc.data[:tc.wantLen]...)
+			sort.Ints(want)
+			res := make([]int, 0, q.Len())
+			for q.Len() > 0 {
+				val, err := q.Dequeue()
+				require.NoError(t, err)
+				res = append(res, val)
+			}
+			assert.Equal(t, want, res)
+			_, err = q.Dequeue()
+			assert.Equal(t, ErrEmptyQueue, err)
+			_, err = q.Peek()
+			assert.Equal(t, ErrEmptyQueue, err)
+		})
+	}
+}
+
+func TestIndexedPriorityQueue_Update(t *testing.T) {
+	testCases := []struct {
+		name    string
+		target  int
+		val     int
+		wantRes []int
+	}{
+		{
+			name:    "decrease",
+			target:  5,
+			val:     0,
+			wantRes: []int{0, 1, 2, 3, 4, 6},
+		},
+		{
+			name:    "increase",
+			target:  1,
+			val:     10,
+			wantRes: []int{2, 3, 4, 5, 6, 10},
+		},
+		{
+			name:    "unchanged",
+			target:  3,
+			val:     3,
+			wantRes: []int{1, 2, 3, 4, 5, 6},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, handles := indexedPriorityQueueOf(t, 6, 5, 4, 3, 2, 1)
+			require.NoError(t, q.Update(handles[tc.target], tc.val))
+			assert.Equal(t, tc.val, handles[tc.target].Value())
+			assert.Equal(t, tc.wantRes, drainIndexedPriorityQueue(t, q))
+		})
+	}
+}
+
+func TestIndexedPriorityQueue_Fix(t *testing.T) {
+	q := NewIndexedPriorityQueue[*int](0, func(src *int, dst *int) int {
+		return compare()(*src, *dst)
+	})
+	vals := []int{3, 1, 2}
+	handles := make([]*Handle[*int], 0, len(vals))
+	for i := range vals {
+		h, err := q.Enqueue(&vals[i])
+		require.NoError(t, err)
+		handles = append(handles, h)
+	}
+	// 直接修改元素，然后调整
+	vals[0] = 0
+	require.NoError(t, q.Fix(handles[0]))
+	val, err := q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, 0, *val)
+
+	_, err = q.Dequeue()
+	require.NoError(t, err)
+	// 已经出队的元素
+	assert.False(t, q.Contains(handles[0]))
+	assert.Equal(t, ErrInvalidHandle, q.Fix(handles[0]))
+	assert.Equal(t, ErrInvalidHandle, q.Update(handles[0], &vals[0]))
+}
+
+func TestIndexedPriorityQueue_Remove(t *testing.T) {
+	q, handles := indexedPriorityQueueOf(t, 6, 5, 4, 3, 2, 1)
+	val, err := q.Remove(handles[3])
+	require.NoError(t, err)
+	assert.Equal(t, 3, val)
+	assert.False(t, q.Contains(handles[3]))
+	assert.True(t, q.Contains(handles[4]))
+	_, err = q.Remove(handles[3])
+	assert.Equal(t, ErrInvalidHandle, err)
+
+	// 删除最后一个元素
+	val, err = q.Remove(q.data[q.Len()-1])
+	require.NoError(t, err)
+	assert.NotEqual(t, 3, val)
+	assert.Equal(t, 4, q.Len())
+
+	// 其它队列的句柄
+	other, otherHandles := indexedPriorityQueueOf(t, 1)
+	assert.False(t, q.Contains(otherHandles[1]))
+	_, err = q.Remove(otherHandles[1])
+	assert.Equal(t, ErrInvalidHandle, err)
+	assert.True(t, other.Contains(otherHandles[1]))
+	assert.False(t, q.Contains(nil))
+}
+
+// 随机操作之后，堆的性质依旧成立
+func TestIndexedPriorityQueue_Random(t *testing.T) {
+	q := NewIndexedPriorityQueue[int](0, compare())
+	handles := make([]*Handle[int], 0, 1000)
+	for i := 0; i < 1000; i++ {
+		h, err := q.Enqueue(rand.Intn(1000))
+		require.NoError(t, err)
+		handles = append(handles, h)
+	}
+	for i, h := range handles {
+		switch i % 3 {
+		case 0:
+			_, err := q.Remove(h)
+			require.NoError(t, err)
+		case 1:
+			require.NoError(t, q.Update(h, rand.Intn(1000)))
+		}
+	}
+	res := drainIndexedPriorityQueue(t, q)
+	assert.Equal(t, 666, len(res))
+	assert.True(t, sort.IntsAreSorted(res))
+}
+
+func ExampleIndexedPriorityQueue() {
+	q := NewIndexedPriorityQueue[int](0, compare())
+	h, _ := q.Enqueue(10)
+	_, _ = q.Enqueue(5)
+	// 把 10 调整为 1，于是它变成了优先级最高的元素
+	_ = q.Update(h, 1)
+	val, _ := q.Dequeue()
+	fmt.Println(val)
+	// Output:
+	// 1
+}
+
+func indexedPriorityQueueOf(t *testing.T, vals ...int) (*IndexedPriorityQueue[int], map[int]*Handle[int]) {
+	q := NewIndexedPriorityQueue[int](0, compare())
+	handles := make(map[int]*Handle[int], len(vals))
+	for _, val := range vals {
+		h, err := q.Enqueue(val)
+		require.NoError(t, err)
+		handles[val] = h
+	}
+	return q, handles
+}
+
+func drainIndexedPriorityQueue(t *testing.T, q *IndexedPriorityQueue[int]) []int {
+	res := make([]int, 0, q.Len())
+	for q.Len() > 0 {
+		val, err := q.Dequeue()
+		require.NoError(t, err)
+		res = append(res, val)
+	}
+	return res
+}
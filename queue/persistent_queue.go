@@ -0,0 +1,776 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ecodeclub/ekit/bean/option"
+)
+
+const (
+	offsetFileName = "consumer.offset"
+	ackFileName    = "consumer.acks"
+	// offset 文件和 ack 文件都由这样的记录组成：8 个字节的 offset + 4 个字节的 crc32 校验和
+	offsetRecordSize = 12
+	// ack 文件里面的记录超过这个数量，并且一半以上都已经过期的时候，重写整个文件
+	ackRewriteThreshold = 1024
+
+	defaultSegmentSize  = 64 << 20
+	defaultSyncInterval = time.Second
+)
+
+var _ BlockingQueue[any] = &PersistentQueue[any]{}
+
+// SyncPolicy 控制 PersistentQueue 什么时候调用 fsync
+type SyncPolicy int
+
+const (
+	// SyncInterval 每隔一段时间 fsync 一次，这是默认策略
+	// 机器宕机的时候最多丢失一个间隔内写入的数据
+	SyncInterval SyncPolicy = iota
+	// SyncAlways 每次写入之后都 fsync，最安全也最慢
+	SyncAlways
+	// SyncNone 从不主动 fsync，完全交给操作系统
+	// 进程崩溃不会丢数据，但是机器宕机的时候可能丢数据
+	SyncNone
+)
+
+// PersistentMessage 是从 PersistentQueue 中取出的消息
+// 处理完毕之后需要调用 PersistentQueue.Ack 确认
+type PersistentMessage[T any] struct {
+	// Offset 消息在队列中的唯一编号，单调递增
+	Offset uint64
+	Value  T
+}
+
+// PersistentQueue 基于本地文件的持久化无界阻塞队列
+// 消息按照顺序追加写入到 segment 文件里面，单个 segment 写满之后会创建新的 segment。
+// 消费者确认过的消息的 offset 会记录在单独的文件里面，
+// 重启之后会从第一条未确认的消息开始继续消费，也就是至少一次的语义。
+// 所有消息都已经被确认的 segment 会被删除。
+// 注意，队列没有超时重新投递的机制，取出之后没有确认的消息只有在重启之后才会被再次取出，
+// 在此之前它所在的 segment 都不会被删除。
+// 同一个目录只能被一个 PersistentQueue 使用
+type PersistentQueue[T any] struct {
+	dir          string
+	codec        Codec[T]
+	syncPolicy   SyncPolicy
+	syncInterval time.Duration
+	segmentSize  int64
+
+	mutex    *sync.Mutex
+	notEmpty *cond
+
+	segments []*segment
+	// writer 指向最后一个 segment
+	writer *os.File
+	// writeOffset 下一条写入的消息的 offset
+	writeOffset uint64
+
+	reader *os.File
+	// readIdx 正在读取的 segment 在 segments 中的下标
+	readIdx int
+	// readPos 下一条消息在 segment 中的位置
+	readPos int64
+	// readOffset 下一条取出的消息的 offset
+	readOffset uint64
+
+	offsetFile *os.File
+	// committed 在它之前的消息都已经被确认了
+	committed uint64
+	// acked 已经确认了，但是因为前面有消息没有确认，所以还不能提交的 offset
+	// 这些乱序确认会追加写入到 ackFile 里面，避免重启之后被重复投递
+	acked   map[uint64]struct{}
+	ackFile *os.File
+	// ackEntries ackFile 里面的记录数量
+	ackEntries int
+	// ahead 在 readOffset 之后、但是不需要再投递的消息数量，
+	// 也就是重启之前就已经乱序确认过的消息，以及已经被删除的 segment 里面的消息
+	ahead uint64
+
+	// dirty 为 true 表示有数据还没有 fsync
+	dirty  bool
+	closed bool
+	stop   chan struct{}
+}
+
+// NewPersistentQueue 在 dir 目录下创建或者恢复一个持久化队列
+// 目录不存在的时候会自动创建
+func NewPersistentQueue[T any](dir string, opts ...option.Option[PersistentQueue[T]]) (*PersistentQueue[T], error) {
+	mutex := &sync.Mutex{}
+	res := &PersistentQueue[T]{
+		dir:          dir,
+		codec:        JSONCodec[T]{},
+		syncPolicy:   SyncInterval,
+		syncInterval: defaultSyncInterval,
+		segmentSize:  defaultSegmentSize,
+		mutex:        mutex,
+		notEmpty:     newCond(mutex),
+		acked:        make(map[uint64]struct{}),
+	}
+	option.Apply(res, opts...)
+	if res.syncPolicy == SyncInterval && res.syncInterval <= 0 {
+		return nil, fmt.Errorf("ekit: fsync 的间隔必须是正数，实际值 %s", res.syncInterval)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := res.recover(); err != nil {
+		_ = res.closeFiles()
+		return nil, err
+	}
+	if res.syncPolicy == SyncInterval {
+		res.stop = make(chan struct{})
+		go res.syncLoop()
+	}
+	return res, nil
+}
+
+// PersistentQueueWithCodec 指定编解码方式，默认是 JSONCodec
+func PersistentQueueWithCodec[T any](codec Codec[T]) option.Option[PersistentQueue[T]] {
+	return func(q *PersistentQueue[T]) {
+		q.codec = codec
+	}
+}
+
+// PersistentQueueWithSyncPolicy 指定 fsync 策略，默认是 SyncInterval
+func PersistentQueueWithSyncPolicy[T any](policy SyncPolicy) option.Option[PersistentQueue[T]] {
+	return func(q *PersistentQueue[T]) {
+		q.syncPolicy = policy
+	}
+}
+
+// PersistentQueueWithSyncInterval 使用 SyncInterval 策略，并且指定间隔，默认间隔是一秒
+// 间隔必须是正数，否则 NewPersistentQueue 会返回错误
+func PersistentQueueWithSyncInterval[T any](interval time.Duration) option.Option[PersistentQueue[T]] {
+	return func(q *PersistentQueue[T]) {
+		q.syncPolicy = SyncInterval
+		q.syncInterval = interval
+	}
+}
+
+// PersistentQueueWithSegmentSize 指定单个 segment 文件的大小，默认是 64MB
+// 单条消息超过该大小的时候，依旧会被完整写入到一个 segment 里面
+func PersistentQueueWithSegmentSize[T any](size int64) option.Option[PersistentQueue[T]] {
+	return func(q *PersistentQueue[T]) {
+		q.segmentSize = size
+	}
+}
+
+// Enqueue 将元素编码之后追加写入到文件中
+// 队列是无界的，所以除非 ctx 已经过期，或者遇到了 IO 错误，否则入队总是能够成功
+func (p *PersistentQueue[T]) Enqueue(ctx context.Context, t T) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	data, err := p.codec.Encode(t)
+	if err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	if err = p.append(data); err != nil {
+		p.mutex.Unlock()
+		return err
+	}
+	// 这里会释放锁
+	p.notEmpty.broadcast()
+	return nil
+}
+
+// Dequeue 取出一条消息，并且立刻确认
+// 如果需要在处理完毕之后再确认，请使用 DequeueMessage 和 Ack
+func (p *PersistentQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	msg, err := p.dequeue(ctx, true)
+	return msg.Value, err
+}
+
+// DequeueMessage 取出一条消息，队列为空的时候会阻塞
+// 取出的消息需要调用 Ack 确认，没有确认的消息在重启之后会被再次取出
+// 队列关闭之后返回 ErrQueueClosed，未取出的消息依旧保存在文件里面
+// 读取文件失败的时候，当前 segment 里面剩余的消息会被跳过，避免一直重试同一个错误
+func (p *PersistentQueue[T]) DequeueMessage(ctx context.Context) (PersistentMessage[T], error) {
+	return p.dequeue(ctx, false)
+}
+
+// Ack 确认 offset 对应的消息已经处理完毕
+// 确认可以是乱序的，乱序的确认会记录在单独的文件里面，重启之后这些消息不会被再次取出
+// 重复确认不会有任何效果
+func (p *PersistentQueue[T]) Ack(offset uint64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		return ErrQueueClosed
+	}
+	return p.ack(offset)
+}
+
+// Len 返回还没有被取出的消息的数量
+func (p *PersistentQueue[T]) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return int(p.writeOffset - p.readOffset - p.ahead)
+}
+
+// Close 关闭队列，并且关闭所有的文件
+// 和内存中的阻塞队列不同，关闭之后 DequeueMessage 会直接返回 ErrQueueClosed，
+// 剩余的消息依旧保存在文件里面，重新打开之后可以继续消费
+// 重复调用 Close 不会有任何效果
+func (p *PersistentQueue[T]) Close() error {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil
+	}
+	p.closed = true
+	if p.stop != nil {
+		close(p.stop)
+	}
+	var err error
+	if p.syncPolicy != SyncNone {
+		err = p.syncFiles()
+	}
+	err = errors.Join(err, p.closeFiles())
+	// 唤醒所有等待的 goroutine，这里会释放锁
+	p.notEmpty.broadcast()
+	return err
+}
+
+// recover 加载所有的 segment 和已经提交的 offset，并且定位到第一条未取出的消息
+func (p *PersistentQueue[T]) recover() error {
+	segs, err := loadSegments(p.dir)
+	if err != nil {
+		return err
+	}
+	p.offsetFile, err = os.OpenFile(filepath.Join(p.dir, offsetFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	p.ackFile, err = os.OpenFile(filepath.Join(p.dir, ackFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	committed, err := p.readCommitted()
+	if err != nil {
+		return err
+	}
+	if len(segs) == 0 {
+		segs = append(segs, &segment{path: segmentPath(p.dir, committed), base: committed})
+	}
+	p.segments = segs
+	last := segs[len(segs)-1]
+	p.writeOffset = last.base + last.count
+	// 在没有 fsync 的情况下，offset 可能比数据先落盘
+	if committed > p.writeOffset {
+		committed = p.writeOffset
+	}
+	// committed 所在的 segment 已经被删除了
+	if committed < p.writeOffset && p.segmentOf(committed) == nil {
+		committed = p.nextBase(committed)
+	}
+	p.committed = committed
+	p.readOffset = committed
+
+	p.writer, err = os.OpenFile(last.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	idx := 0
+	for idx < len(segs)-1 && segs[idx+1].base <= committed {
+		idx++
+	}
+	if err = p.openReader(idx); err != nil {
+		return err
+	}
+	seg := segs[idx]
+	p.readPos, err = skipRecords(p.reader, committed-seg.base, seg.size)
+	if err != nil {
+		return err
+	}
+	// 中间被删除的 segment 里面的消息都已经确认过了
+	for i := idx + 1; i < len(segs); i++ {
+		p.ahead += segs[i].base - (segs[i-1].base + segs[i-1].count)
+	}
+	if err = p.loadAcks(); err != nil {
+		return err
+	}
+	// 上一次可能在提交或者删除 segment 之前就崩溃了
+	return p.commit()
+}
+
+// loadAcks 加载乱序确认的 offset，已经提交或者所在 segment 已经被删除的记录会被忽略
+// 损坏的记录以及之后的记录都会被丢弃，最坏的情况也只是重复投递
+func (p *PersistentQueue[T]) loadAcks() error {
+	data, err := io.ReadAll(p.ackFile)
+	if err != nil {
+		return err
+	}
+	for ; len(data) >= offsetRecordSize; data = data[offsetRecordSize:] {
+		offset, ok := decodeOffset(data[:offsetRecordSize])
+		if !ok {
+			break
+		}
+		p.ackEntries++
+		if offset < p.committed {
+			continue
+		}
+		seg := p.segmentOf(offset)
+		if seg == nil {
+			continue
+		}
+		if _, ok = p.acked[offset]; ok {
+			continue
+		}
+		p.acked[offset] = struct{}{}
+		seg.acked++
+		p.ahead++
+	}
+	return nil
+}
+
+// readCommitted 读取 offset 文件，文件为空或者损坏的时候返回 0
+func (p *PersistentQueue[T]) readCommitted() (uint64, error) {
+	var buf [offsetRecordSize]byte
+	_, err := p.offsetFile.ReadAt(buf[:], 0)
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	committed, _ := decodeOffset(buf[:])
+	return committed, nil
+}
+
+func (p *PersistentQueue[T]) writeCommitted() error {
+	var buf [offsetRecordSize]byte
+	encodeOffset(buf[:], p.committed)
+	if _, err := p.offsetFile.WriteAt(buf[:], 0); err != nil {
+		return err
+	}
+	return p.sync(p.offsetFile)
+}
+
+// appendAck 将乱序确认的 offset 追加写入到 ack 文件
+func (p *PersistentQueue[T]) appendAck(offset uint64) error {
+	var buf [offsetRecordSize]byte
+	encodeOffset(buf[:], offset)
+	// 上一次加载的时候可能丢弃了损坏的记录，所以这里按照记录数量计算位置，直接覆盖掉
+	if _, err := p.ackFile.WriteAt(buf[:], int64(p.ackEntries)*offsetRecordSize); err != nil {
+		return err
+	}
+	p.ackEntries++
+	return p.sync(p.ackFile)
+}
+
+// rewriteAcks 在 ack 文件里面过期的记录太多的时候，只保留还没有提交的记录
+// 重写的过程中崩溃会丢掉一部分乱序确认，这只会导致重复投递
+func (p *PersistentQueue[T]) rewriteAcks() error {
+	switch {
+	case p.ackEntries == 0:
+		return nil
+	case len(p.acked) == 0:
+		// 全部都已经提交了，直接清空
+	case p.ackEntries < ackRewriteThreshold || p.ackEntries <= 2*len(p.acked):
+		return nil
+	}
+	buf := make([]byte, len(p.acked)*offsetRecordSize)
+	i := 0
+	for offset := range p.acked {
+		encodeOffset(buf[i:i+offsetRecordSize], offset)
+		i += offsetRecordSize
+	}
+	if err := p.ackFile.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := p.ackFile.WriteAt(buf, 0); err != nil {
+		return err
+	}
+	p.ackEntries = len(p.acked)
+	return p.sync(p.ackFile)
+}
+
+// append 追加写入一条消息，必须在锁范围内调用
+func (p *PersistentQueue[T]) append(data []byte) error {
+	seg := p.segments[len(p.segments)-1]
+	record := encodeRecord(data)
+	if seg.size > 0 && seg.size+int64(len(record)) > p.segmentSize {
+		if err := p.roll(); err != nil {
+			return err
+		}
+		seg = p.segments[len(p.segments)-1]
+	}
+	n, err := p.writer.Write(record)
+	if err != nil {
+		// 尽量把写了一半的记录截掉，截不掉也没关系，恢复的时候会处理
+		_ = p.writer.Truncate(seg.size)
+		return err
+	}
+	seg.size += int64(n)
+	seg.count++
+	p.writeOffset++
+	return p.sync(p.writer)
+}
+
+// roll 创建一个新的 segment 用于写入
+func (p *PersistentQueue[T]) roll() error {
+	if p.syncPolicy != SyncNone {
+		if err := p.writer.Sync(); err != nil {
+			return err
+		}
+	}
+	seg := &segment{path: segmentPath(p.dir, p.writeOffset), base: p.writeOffset}
+	writer, err := os.OpenFile(seg.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if p.syncPolicy == SyncAlways {
+		if err = syncDir(p.dir); err != nil {
+			_ = writer.Close()
+			return err
+		}
+	}
+	_ = p.writer.Close()
+	p.writer = writer
+	p.segments = append(p.segments, seg)
+	return nil
+}
+
+// dequeue 取出一条消息，autoAck 为 true 的时候在同一个锁里面确认
+// 这样取出和确认之间不会插入 Close，避免消息已经取出了却因为无法确认而丢失
+func (p *PersistentQueue[T]) dequeue(ctx context.Context, autoAck bool) (PersistentMessage[T], error) {
+	var msg PersistentMessage[T]
+	if ctx.Err() != nil {
+		return msg, ctx.Err()
+	}
+	p.mutex.Lock()
+	for !p.closed && p.readOffset+p.ahead == p.writeOffset {
+		signal := p.notEmpty.signalCh()
+		select {
+		case <-ctx.Done():
+			return msg, ctx.Err()
+		case <-signal:
+			p.mutex.Lock()
+		}
+	}
+	defer p.mutex.Unlock()
+	if p.closed {
+		return msg, ErrQueueClosed
+	}
+	offset, payload, err := p.read()
+	if err != nil {
+		// 读取失败的原因多半是文件损坏了，重试也没有用，只能跳过
+		n, commitErr := p.skipBroken()
+		err = fmt.Errorf("ekit: 读取消息失败，已跳过 %d 条消息 %w", n, err)
+		if commitErr != nil {
+			return msg, errors.Join(err, commitErr)
+		}
+		return msg, err
+	}
+	val, err := p.codec.Decode(payload)
+	if err != nil {
+		// 无法解码的消息永远都不可能被消费，所以直接跳过，避免阻塞后面的消息
+		if ackErr := p.ack(offset); ackErr != nil {
+			return msg, errors.Join(err, ackErr)
+		}
+		return msg, fmt.Errorf("ekit: 解码 offset 为 %d 的消息失败，已跳过该消息 %w", offset, err)
+	}
+	msg.Offset = offset
+	msg.Value = val
+	if autoAck {
+		return msg, p.ack(offset)
+	}
+	return msg, nil
+}
+
+// read 读取下一条需要投递的消息，调用者必须确保还有这样的消息
+func (p *PersistentQueue[T]) read() (uint64, []byte, error) {
+	for {
+		seg := p.segments[p.readIdx]
+		if p.readPos >= seg.size {
+			// 当前 segment 已经读完了
+			if err := p.openReader(p.readIdx + 1); err != nil {
+				return 0, nil, err
+			}
+			// 中间的 segment 可能已经被删除了
+			base := p.segments[p.readIdx].base
+			p.ahead -= base - p.readOffset
+			p.readOffset = base
+			continue
+		}
+		if p.reader == nil {
+			// 之前打开失败了，位置保持不变
+			reader, err := os.Open(seg.path)
+			if err != nil {
+				return 0, nil, err
+			}
+			p.reader = reader
+		}
+		payload, err := readRecord(p.reader, p.readPos, seg.size)
+		if err != nil {
+			return 0, nil, err
+		}
+		p.readPos += int64(recordHeaderSize + len(payload))
+		offset := p.readOffset
+		p.readOffset++
+		if _, ok := p.acked[offset]; ok || offset < p.committed {
+			// 重启之前就已经确认过了
+			p.ahead--
+			continue
+		}
+		return offset, payload, nil
+	}
+}
+
+// skipBroken 在读取失败之后，跳过出错的 segment 里面剩余的消息，返回跳过的消息数量
+// 这些消息会被当作已经确认，否则 committed 永远无法越过它们
+func (p *PersistentQueue[T]) skipBroken() (uint64, error) {
+	idx := p.readIdx
+	if p.readPos >= p.segments[idx].size && idx+1 < len(p.segments) {
+		// 打开下一个 segment 的时候失败了
+		idx++
+		if p.reader != nil {
+			_ = p.reader.Close()
+			p.reader = nil
+		}
+	}
+	seg := p.segments[idx]
+	if p.readOffset < seg.base {
+		p.ahead -= seg.base - p.readOffset
+		p.readOffset = seg.base
+	}
+	var n uint64
+	for ; p.readOffset < seg.base+seg.count; p.readOffset++ {
+		if _, ok := p.acked[p.readOffset]; ok || p.readOffset < p.committed {
+			p.ahead--
+			continue
+		}
+		p.acked[p.readOffset] = struct{}{}
+		seg.acked++
+		n++
+	}
+	p.readIdx = idx
+	p.readPos = seg.size
+	return n, p.commit()
+}
+
+func (p *PersistentQueue[T]) openReader(idx int) error {
+	reader, err := os.Open(p.segments[idx].path)
+	if err != nil {
+		return err
+	}
+	if p.reader != nil {
+		_ = p.reader.Close()
+	}
+	p.reader = reader
+	p.readIdx = idx
+	p.readPos = 0
+	return nil
+}
+
+func (p *PersistentQueue[T]) ack(offset uint64) error {
+	if offset < p.committed {
+		return nil
+	}
+	if offset >= p.readOffset {
+		return fmt.Errorf("ekit: offset 为 %d 的消息还没有被取出", offset)
+	}
+	if _, ok := p.acked[offset]; ok {
+		return nil
+	}
+	seg := p.segmentOf(offset)
+	if seg == nil {
+		// 所在的 segment 已经被删除了，说明早就确认过了
+		return nil
+	}
+	if offset > p.committed {
+		if err := p.appendAck(offset); err != nil {
+			return err
+		}
+	}
+	p.acked[offset] = struct{}{}
+	seg.acked++
+	return p.commit()
+}
+
+// commit 尽可能地推进 committed 并且持久化，之后删除不再需要的 segment
+func (p *PersistentQueue[T]) commit() error {
+	old := p.committed
+	for p.committed < p.writeOffset {
+		if _, ok := p.acked[p.committed]; ok {
+			delete(p.acked, p.committed)
+			p.committed++
+			continue
+		}
+		if p.segmentOf(p.committed) != nil {
+			break
+		}
+		// 跳过已经被删除的 segment
+		p.committed = p.nextBase(p.committed)
+	}
+	if p.committed != old {
+		if err := p.writeCommitted(); err != nil {
+			return err
+		}
+	}
+	return p.compact()
+}
+
+// compact 删除所有消息都已经被确认的 segment，正在写入的 segment 不会被删除
+func (p *PersistentQueue[T]) compact() error {
+	n := 0
+	for n < len(p.segments)-1 && p.segments[n+1].base <= p.committed {
+		n++
+	}
+	if n > 0 {
+		if p.readIdx < n {
+			// 重启之后，committed 可能会越过还没有读到的、之前就已经乱序确认过的消息
+			if base := p.segments[n].base; p.readOffset < base {
+				p.ahead -= base - p.readOffset
+				p.readOffset = base
+			}
+			// 等到读取的时候再打开，避免打开失败导致确认也失败
+			if p.reader != nil {
+				_ = p.reader.Close()
+				p.reader = nil
+			}
+			p.readIdx, p.readPos = n, 0
+		}
+		for _, seg := range p.segments[:n] {
+			if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		p.segments = append([]*segment(nil), p.segments[n:]...)
+		p.readIdx -= n
+	}
+	// 前面有消息没有被确认的时候，后面已经读完并且全部确认过的 segment 也可以删除，
+	// 否则一条一直没有确认的消息会导致磁盘空间无限增长。
+	// 第一个 segment 包含 committed，不可能全部确认过
+	for i := 1; i < p.readIdx; {
+		seg := p.segments[i]
+		if seg.acked < seg.count {
+			i++
+			continue
+		}
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for offset := seg.base; offset < seg.base+seg.count; offset++ {
+			delete(p.acked, offset)
+		}
+		p.segments = append(p.segments[:i], p.segments[i+1:]...)
+		p.readIdx--
+	}
+	return p.rewriteAcks()
+}
+
+// segmentOf 返回 offset 所在的 segment，offset 所在的 segment 已经被删除的时候返回 nil
+func (p *PersistentQueue[T]) segmentOf(offset uint64) *segment {
+	i := sort.Search(len(p.segments), func(i int) bool {
+		return p.segments[i].base > offset
+	}) - 1
+	if i < 0 || offset >= p.segments[i].base+p.segments[i].count {
+		return nil
+	}
+	return p.segments[i]
+}
+
+// nextBase 返回 offset 之后第一个 segment 的 base
+func (p *PersistentQueue[T]) nextBase(offset uint64) uint64 {
+	i := sort.Search(len(p.segments), func(i int) bool {
+		return p.segments[i].base > offset
+	})
+	return p.segments[i].base
+}
+
+func (p *PersistentQueue[T]) sync(f *os.File) error {
+	switch p.syncPolicy {
+	case SyncAlways:
+		return f.Sync()
+	case SyncInterval:
+		p.dirty = true
+	}
+	return nil
+}
+
+func (p *PersistentQueue[T]) syncFiles() error {
+	p.dirty = false
+	return errors.Join(p.writer.Sync(), p.offsetFile.Sync(), p.ackFile.Sync())
+}
+
+func (p *PersistentQueue[T]) syncLoop() {
+	ticker := time.NewTicker(p.syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.mutex.Lock()
+			if !p.closed && p.dirty {
+				// 这里出错了也没有办法通知用户，只能等下一次重试
+				_ = p.syncFiles()
+			}
+			p.mutex.Unlock()
+		}
+	}
+}
+
+func (p *PersistentQueue[T]) closeFiles() error {
+	var err error
+	for _, f := range []*os.File{p.writer, p.reader, p.offsetFile, p.ackFile} {
+		if f != nil {
+			err = errors.Join(err, f.Close())
+		}
+	}
+	return err
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func encodeOffset(buf []byte, offset uint64) {
+	binary.BigEndian.PutUint64(buf[:8], offset)
+	binary.BigEndian.PutUint32(buf[8:offsetRecordSize], crc32.ChecksumIEEE(buf[:8]))
+}
+
+// decodeOffset 解析 offset 记录，校验失败的时候返回 false
+func decodeOffset(buf []byte) (uint64, bool) {
+	if crc32.ChecksumIEEE(buf[:8]) != binary.BigEndian.Uint32(buf[8:offsetRecordSize]) {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(buf[:8]), true
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	segmentFileSuffix = ".seg"
+	// 每一条记录的头部：4 个字节的长度 + 4 个字节的 crc32 校验和
+	recordHeaderSize = 8
+)
+
+// errTornRecord 表示读到了不完整或者校验失败的记录
+// 一般是因为写入的过程中进程崩溃了
+var errTornRecord = errors.New("ekit: 不完整的记录")
+
+// segment 是一个只追加写入的文件，文件名是第一条消息的 offset
+type segment struct {
+	path string
+	// base 第一条消息的 offset
+	base uint64
+	// count 包含多少条消息
+	count uint64
+	// size 文件的有效长度
+	size int64
+	// acked 乱序确认的消息数量，只保存在内存里面
+	acked uint64
+}
+
+func segmentPath(dir string, base uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", base, segmentFileSuffix))
+}
+
+// loadSegments 按照 base 从小到大的顺序加载 dir 下所有的 segment
+// 最后一个 segment 末尾不完整的记录会被截断，其余 segment 如果有损坏的记录则返回错误
+func loadSegments(dir string) ([]*segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	segs := make([]*segment, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentFileSuffix) {
+			continue
+		}
+		base, err := strconv.ParseUint(strings.TrimSuffix(name, segmentFileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		segs = append(segs, &segment{path: filepath.Join(dir, name), base: base})
+	}
+	sort.Slice(segs, func(i, j int) bool {
+		return segs[i].base < segs[j].base
+	})
+	for i, seg := range segs {
+		err = seg.recover(i == len(segs)-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return segs, nil
+}
+
+// recover 扫描整个文件，计算消息数量和有效长度
+// 如果 truncate 为 true，那么末尾不完整的记录会被截断
+func (s *segment) recover(truncate bool) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	var pos int64
+	var count uint64
+	for {
+		n, err := readRecordSize(f, pos, info.Size())
+		if err == io.EOF {
+			break
+		}
+		if err == errTornRecord {
+			if !truncate {
+				return fmt.Errorf("ekit: segment %s 在位置 %d 已经损坏", s.path, pos)
+			}
+			if err = os.Truncate(s.path, pos); err != nil {
+				return err
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		pos += n
+		count++
+	}
+	s.count = count
+	s.size = pos
+	return nil
+}
+
+// readRecordSize 校验 pos 处的记录，返回整条记录的长度
+func readRecordSize(f *os.File, pos int64, limit int64) (int64, error) {
+	payload, err := readRecord(f, pos, limit)
+	if err != nil {
+		return 0, err
+	}
+	return int64(recordHeaderSize + len(payload)), nil
+}
+
+// readRecord 读取 pos 处的记录，limit 是文件的有效长度
+// 恰好读到 limit 的时候返回 io.EOF，记录不完整或者校验失败的时候返回 errTornRecord
+func readRecord(f *os.File, pos int64, limit int64) ([]byte, error) {
+	if pos >= limit {
+		return nil, io.EOF
+	}
+	if pos+recordHeaderSize > limit {
+		return nil, errTornRecord
+	}
+	var header [recordHeaderSize]byte
+	_, err := f.ReadAt(header[:], pos)
+	if err == io.EOF {
+		return nil, errTornRecord
+	}
+	if err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	checksum := binary.BigEndian.Uint32(header[4:])
+	// 长度本身可能就是写坏的，避免分配一个巨大的切片
+	if pos+recordHeaderSize+int64(length) > limit {
+		return nil, errTornRecord
+	}
+	payload := make([]byte, length)
+	_, err = f.ReadAt(payload, pos+recordHeaderSize)
+	if err == io.EOF {
+		return nil, errTornRecord
+	}
+	if err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, errTornRecord
+	}
+	return payload, nil
+}
+
+// skipRecords 从文件开头跳过 n 条记录，返回第 n 条记录的位置
+func skipRecords(f *os.File, n uint64, limit int64) (int64, error) {
+	var pos int64
+	for i := uint64(0); i < n; i++ {
+		size, err := readRecordSize(f, pos, limit)
+		if err != nil {
+			return 0, err
+		}
+		pos += size
+	}
+	return pos, nil
+}
+
+func encodeRecord(payload []byte) []byte {
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:recordHeaderSize], crc32.ChecksumIEEE(payload))
+	copy(buf[recordHeaderSize:], payload)
+	return buf
+}
@@ -0,0 +1,364 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/bean/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPersistentQueue(t *testing.T) {
+	_, err := NewPersistentQueue[int](t.TempDir(), PersistentQueueWithSyncInterval[int](0))
+	assert.EqualError(t, err, "ekit: fsync 的间隔必须是正数，实际值 0s")
+}
+
+func TestPersistentQueue_EnqueueDequeue(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy SyncPolicy
+	}{
+		{
+			name:   "sync interval",
+			policy: SyncInterval,
+		},
+		{
+			name:   "sync always",
+			policy: SyncAlways,
+		},
+		{
+			name:   "sync none",
+			policy: SyncNone,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := NewPersistentQueue[testUser](t.TempDir(),
+				PersistentQueueWithSyncPolicy[testUser](tc.policy),
+				PersistentQueueWithSegmentSize[testUser](64))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, q.Close())
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			for i := 0; i < 10; i++ {
+				err = q.Enqueue(ctx, testUser{Id: i, Name: "Tom" + strconv.Itoa(i)})
+				require.NoError(t, err)
+			}
+			assert.Equal(t, 10, q.Len())
+			// segment 很小，所以肯定写了多个 segment
+			assert.Greater(t, len(q.segments), 1)
+			for i := 0; i < 10; i++ {
+				val, err := q.Dequeue(ctx)
+				require.NoError(t, err)
+				assert.Equal(t, testUser{Id: i, Name: "Tom" + strconv.Itoa(i)}, val)
+			}
+			assert.Equal(t, 0, q.Len())
+			// 所有消息都已经确认了，只剩下正在写入的 segment
+			assert.Equal(t, 1, len(q.segments))
+		})
+	}
+}
+
+func TestPersistentQueue_Dequeue(t *testing.T) {
+	t.Run("invalid context", func(t *testing.T) {
+		q := newPersistentQueue(t, t.TempDir())
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		_, err := q.Dequeue(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		err = q.Enqueue(ctx, 1)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("empty and timeout", func(t *testing.T) {
+		q := newPersistentQueue(t, t.TempDir())
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		_, err := q.Dequeue(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("dequeue blocking and enqueue", func(t *testing.T) {
+		q := newPersistentQueue(t, t.TempDir())
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			assert.NoError(t, q.Enqueue(ctx, 123))
+		}()
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 123, val)
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		q := newPersistentQueue(t, t.TempDir(), PersistentQueueWithCodec[int](errDecodeCodec{}))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, 1))
+		require.NoError(t, q.Enqueue(ctx, 2))
+		_, err := q.Dequeue(ctx)
+		assert.ErrorIs(t, err, errDecode)
+		// 无法解码的消息会被跳过
+		assert.Equal(t, uint64(1), q.committed)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		// 每个 segment 只能放下一条消息
+		q := newPersistentQueue(t, t.TempDir(), PersistentQueueWithSegmentSize[int](16))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, q.Enqueue(ctx, i))
+		}
+		require.Equal(t, 3, len(q.segments))
+		require.NoError(t, os.Remove(q.segments[1].path))
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, val)
+		// 读不到的消息会被跳过，而不是一直重试
+		_, err = q.Dequeue(ctx)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		val, err = q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, val)
+		assert.Equal(t, uint64(3), q.committed)
+	})
+}
+
+func TestPersistentQueue_Ack(t *testing.T) {
+	dir := t.TempDir()
+	q := newPersistentQueue(t, dir, PersistentQueueWithSegmentSize[int](32))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, q.Enqueue(ctx, i))
+	}
+	msgs := make([]PersistentMessage[int], 0, 5)
+	for i := 0; i < 4; i++ {
+		msg, err := q.DequeueMessage(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i), msg.Offset)
+		assert.Equal(t, i, msg.Value)
+		msgs = append(msgs, msg)
+	}
+
+	// 还没有取出的消息不能确认
+	err := q.Ack(4)
+	assert.Error(t, err)
+
+	// 乱序确认，1 没有确认，所以 2 不会被提交
+	require.NoError(t, q.Ack(msgs[2].Offset))
+	require.NoError(t, q.Ack(msgs[0].Offset))
+	assert.Equal(t, uint64(1), q.committed)
+	// 重复确认
+	require.NoError(t, q.Ack(msgs[0].Offset))
+	require.NoError(t, q.Ack(msgs[1].Offset))
+	assert.Equal(t, uint64(3), q.committed)
+	require.NoError(t, q.Close())
+	assert.Equal(t, ErrQueueClosed, q.Ack(msgs[3].Offset))
+
+	// 重启之后，没有确认的消息会被再次取出
+	q = newPersistentQueue(t, dir, PersistentQueueWithSegmentSize[int](32))
+	assert.Equal(t, 2, q.Len())
+	msg, err := q.DequeueMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, PersistentMessage[int]{Offset: 3, Value: 3}, msg)
+	val, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, val)
+}
+
+func TestPersistentQueue_Close(t *testing.T) {
+	dir := t.TempDir()
+	q := newPersistentQueue(t, dir)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := q.Dequeue(ctx)
+		assert.Equal(t, ErrQueueClosed, err)
+	}()
+	time.Sleep(time.Millisecond * 100)
+	require.NoError(t, q.Close())
+	require.NoError(t, q.Close())
+	wg.Wait()
+	assert.Equal(t, ErrQueueClosed, q.Enqueue(ctx, 1))
+}
+
+func TestPersistentQueue_Recover(t *testing.T) {
+	t.Run("reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		q := newPersistentQueue(t, dir, PersistentQueueWithSegmentSize[int](32))
+		for i := 0; i < 10; i++ {
+			require.NoError(t, q.Enqueue(ctx, i))
+		}
+		for i := 0; i < 6; i++ {
+			_, err := q.Dequeue(ctx)
+			require.NoError(t, err)
+		}
+		require.NoError(t, q.Close())
+
+		q = newPersistentQueue(t, dir, PersistentQueueWithSegmentSize[int](32))
+		assert.Equal(t, 4, q.Len())
+		require.NoError(t, q.Enqueue(ctx, 10))
+		for i := 6; i <= 10; i++ {
+			val, err := q.Dequeue(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, i, val)
+		}
+	})
+
+	// 写到一半的时候进程崩溃了
+	t.Run("torn tail", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		q := newPersistentQueue(t, dir)
+		require.NoError(t, q.Enqueue(ctx, 1))
+		require.NoError(t, q.Enqueue(ctx, 2))
+		path := q.segments[0].path
+		size := q.segments[0].size
+		require.NoError(t, q.Close())
+		require.NoError(t, os.Truncate(path, size-1))
+
+		q = newPersistentQueue(t, dir)
+		assert.Equal(t, 1, q.Len())
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, val)
+		require.NoError(t, q.Enqueue(ctx, 3))
+		val, err = q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, val)
+	})
+
+	// 中间的 segment 损坏了，这种情况无法恢复
+	t.Run("corrupted segment", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		q := newPersistentQueue(t, dir, PersistentQueueWithSegmentSize[int](16))
+		for i := 0; i < 3; i++ {
+			require.NoError(t, q.Enqueue(ctx, i))
+		}
+		path := q.segments[0].path
+		require.NoError(t, q.Close())
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		require.NoError(t, err)
+		_, err = f.WriteAt([]byte{0xff}, recordHeaderSize)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		_, err = NewPersistentQueue[int](dir)
+		assert.Error(t, err)
+	})
+
+	// 一条消息一直没有确认，后面乱序确认的消息既不会占用磁盘，重启之后也不会被再次取出
+	t.Run("out of order ack", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		q := newPersistentQueue(t, dir, PersistentQueueWithSegmentSize[int](32))
+		for i := 0; i < 20; i++ {
+			require.NoError(t, q.Enqueue(ctx, i))
+		}
+		for i := 0; i < 20; i++ {
+			msg, err := q.DequeueMessage(ctx)
+			require.NoError(t, err)
+			if i > 0 {
+				require.NoError(t, q.Ack(msg.Offset))
+			}
+		}
+		assert.Equal(t, uint64(0), q.committed)
+		// 只剩下没有确认的消息所在的 segment 和正在写入的 segment
+		assert.Equal(t, 2, len(q.segments))
+		require.NoError(t, q.Close())
+
+		q = newPersistentQueue(t, dir, PersistentQueueWithSegmentSize[int](32))
+		assert.Equal(t, 1, q.Len())
+		require.NoError(t, q.Enqueue(ctx, 20))
+		assert.Equal(t, 2, q.Len())
+		val, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, val)
+		assert.Equal(t, uint64(20), q.committed)
+		val, err = q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 20, val)
+		assert.Equal(t, 0, q.Len())
+		assert.Equal(t, 1, len(q.segments))
+		// 所有的乱序确认都已经提交了，ack 文件会被清空
+		info, err := os.Stat(filepath.Join(dir, ackFileName))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), info.Size())
+	})
+
+	// offset 文件损坏的时候从第一个 segment 开始消费
+	t.Run("corrupted offset", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		q := newPersistentQueue(t, dir)
+		require.NoError(t, q.Enqueue(ctx, 1))
+		require.NoError(t, q.Enqueue(ctx, 2))
+		_, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NoError(t, q.Close())
+		require.NoError(t, os.WriteFile(filepath.Join(dir, offsetFileName), []byte("invalid offset"), 0o644))
+
+		q = newPersistentQueue(t, dir)
+		assert.Equal(t, 2, q.Len())
+	})
+}
+
+func newPersistentQueue(t *testing.T, dir string, opts ...option.Option[PersistentQueue[int]]) *PersistentQueue[int] {
+	q, err := NewPersistentQueue[int](dir, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = q.Close()
+	})
+	return q
+}
+
+type testUser struct {
+	Id   int
+	Name string
+}
+
+var errDecode = errors.New("decode error")
+
+type errDecodeCodec struct {
+	JSONCodec[int]
+}
+
+func (errDecodeCodec) Decode(data []byte) (int, error) {
+	return 0, errDecode
+}
@@ -0,0 +1,266 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ecodeclub/ekit/internal/errs"
+)
+
+// 默认的各层时间轮的大小，和 Linux 内核的设计类似
+var defaultWheelSizes = []int{256, 64, 64, 64}
+
+// TimingWheel 层级时间轮
+// 添加和取消定时器的时间复杂度都是 O(1)，适合管理海量的短时定时器，例如连接超时和会话超时。
+// 第 0 层每个槽代表一个 tick，第 i 层每个槽代表第 i-1 层转一圈的时间。
+// 超过最高层范围的定时器会被暂时放到最高层，到时候再重新分配。
+// 定时器到期的精度是 tick，也就是说定时器总是会在到期之后的一个 tick 之内触发，不会提前触发
+type TimingWheel struct {
+	tick  time.Duration
+	start time.Time
+
+	mutex  sync.Mutex
+	levels []*wheelLevel
+	// current 当前已经走过的 tick 数
+	current int64
+	// count 还没有触发的定时器数量
+	count int
+
+	closed bool
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+type wheelLevel struct {
+	// span 每个槽代表多少个 tick
+	span  int64
+	slots []*Timer
+}
+
+// NewTimingWheel 创建一个时间轮并且立刻开始转动
+// tick 是最小的时间精度，wheelSizes 是从低到高每一层的槽数，不传的时候使用默认值
+// 用完之后需要调用 Close 停止时间轮
+func NewTimingWheel(tick time.Duration, wheelSizes ...int) (*TimingWheel, error) {
+	if tick <= 0 {
+		return nil, errs.NewErrInvalidIntervalValue(tick)
+	}
+	if len(wheelSizes) == 0 {
+		wheelSizes = defaultWheelSizes
+	}
+	levels := make([]*wheelLevel, 0, len(wheelSizes))
+	span := int64(1)
+	for _, size := range wheelSizes {
+		if size <= 0 {
+			return nil, fmt.Errorf("ekit: 时间轮的大小必须是正数，实际值 %d", size)
+		}
+		slots := make([]*Timer, size)
+		for i := range slots {
+			// 哨兵结点
+			sentinel := &Timer{}
+			sentinel.prev, sentinel.next = sentinel, sentinel
+			slots[i] = sentinel
+		}
+		levels = append(levels, &wheelLevel{span: span, slots: slots})
+		span *= int64(size)
+	}
+	tw := &TimingWheel{
+		tick:   tick,
+		start:  time.Now(),
+		levels: levels,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go tw.run()
+	return tw, nil
+}
+
+// AfterFunc 在 d 之后，在一个单独的 goroutine 中执行 f
+// 返回的 Timer 可以用于取消
+// 时间轮已经关闭的时候，f 永远不会被执行
+func (tw *TimingWheel) AfterFunc(d time.Duration, f func()) *Timer {
+	return tw.afterFunc(d, func() {
+		go f()
+	})
+}
+
+// Len 返回还没有触发的定时器数量
+func (tw *TimingWheel) Len() int {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	return tw.count
+}
+
+// Close 停止时间轮，所有还没有触发的定时器都不会再触发
+// 重复调用 Close 不会有任何效果
+func (tw *TimingWheel) Close() error {
+	tw.mutex.Lock()
+	if tw.closed {
+		tw.mutex.Unlock()
+		return nil
+	}
+	tw.closed = true
+	tw.mutex.Unlock()
+	close(tw.stop)
+	<-tw.done
+	return nil
+}
+
+// afterFunc 在 d 之后执行 f，f 是在时间轮的 goroutine 里面执行的，所以 f 不能阻塞
+func (tw *TimingWheel) afterFunc(d time.Duration, f func()) *Timer {
+	t := &Timer{tw: tw, f: f}
+	if tw.schedule(t, d) {
+		f()
+	}
+	return t
+}
+
+// schedule 将 t 放入时间轮，如果 t 已经到期了，那么返回 true，由调用者负责执行
+// 时间轮已经关闭的情况下什么也不做
+func (tw *TimingWheel) schedule(t *Timer, d time.Duration) bool {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if tw.closed {
+		return false
+	}
+	// 向上取整，确保不会提前触发
+	elapsed := time.Since(tw.start) + d
+	t.expiration = int64((elapsed + tw.tick - 1) / tw.tick)
+	if d <= 0 || t.expiration <= tw.current {
+		return true
+	}
+	tw.add(t)
+	return false
+}
+
+// add 将定时器放入合适的槽，必须在锁范围内调用
+func (tw *TimingWheel) add(t *Timer) {
+	delta := t.expiration - tw.current
+	top := tw.levels[len(tw.levels)-1]
+	level := top
+	for _, l := range tw.levels {
+		if delta < l.span*int64(len(l.slots)) {
+			level = l
+			break
+		}
+	}
+	sentinel := level.slots[(t.expiration/level.span)%int64(len(level.slots))]
+	t.sentinel = sentinel
+	t.prev = sentinel.prev
+	t.next = sentinel
+	sentinel.prev.next = t
+	sentinel.prev = t
+	tw.count++
+}
+
+// remove 将定时器从槽里面移除，必须在锁范围内调用
+func (tw *TimingWheel) remove(t *Timer) {
+	t.prev.next = t.next
+	t.next.prev = t.prev
+	t.prev, t.next, t.sentinel = nil, nil, nil
+	tw.count--
+}
+
+func (tw *TimingWheel) run() {
+	defer close(tw.done)
+	ticker := time.NewTicker(tw.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-tw.stop:
+			return
+		case now := <-ticker.C:
+			tw.advance(int64(now.Sub(tw.start) / tw.tick))
+		}
+	}
+}
+
+// advance 一个 tick 一个 tick 地转动到 target，并且执行所有到期的定时器
+// ticker 可能会丢失 tick，所以不能简单地每次只转动一个 tick
+func (tw *TimingWheel) advance(target int64) {
+	var expired []*Timer
+	tw.mutex.Lock()
+	for tw.current < target {
+		tw.current++
+		// 先把高层的定时器降级，再处理第 0 层
+		for i := len(tw.levels) - 1; i > 0; i-- {
+			l := tw.levels[i]
+			if tw.current%l.span != 0 {
+				continue
+			}
+			expired = tw.cascade(l.slots[(tw.current/l.span)%int64(len(l.slots))], expired)
+		}
+		l := tw.levels[0]
+		expired = tw.cascade(l.slots[tw.current%int64(len(l.slots))], expired)
+	}
+	tw.mutex.Unlock()
+	for _, t := range expired {
+		t.f()
+	}
+}
+
+// cascade 将槽里面的定时器重新放入时间轮，已经到期的定时器追加到 expired
+// 超出最高层范围的定时器可能会被重新放回同一个槽，所以要先把整个链表摘下来
+func (tw *TimingWheel) cascade(sentinel *Timer, expired []*Timer) []*Timer {
+	t := sentinel.next
+	sentinel.prev, sentinel.next = sentinel, sentinel
+	for t != sentinel {
+		next := t.next
+		t.prev, t.next, t.sentinel = nil, nil, nil
+		tw.count--
+		if t.expiration <= tw.current {
+			expired = append(expired, t)
+		} else {
+			tw.add(t)
+		}
+		t = next
+	}
+	return expired
+}
+
+// Timer 是时间轮中的定时器
+type Timer struct {
+	tw         *TimingWheel
+	f          func()
+	expiration int64
+	// onStop 在 Stop 成功之后调用，调用的时候已经释放了时间轮的锁
+	onStop func()
+
+	// 同一个槽里面的定时器组成了双向循环链表
+	prev     *Timer
+	next     *Timer
+	sentinel *Timer
+}
+
+// Stop 取消定时器
+// 如果定时器还没有触发，返回 true；如果已经触发了或者已经取消了，返回 false
+func (t *Timer) Stop() bool {
+	if t.tw == nil {
+		return false
+	}
+	t.tw.mutex.Lock()
+	if t.sentinel == nil {
+		t.tw.mutex.Unlock()
+		return false
+	}
+	t.tw.remove(t)
+	t.tw.mutex.Unlock()
+	if t.onStop != nil {
+		t.onStop()
+	}
+	return true
+}
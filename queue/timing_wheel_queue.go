@@ -0,0 +1,121 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+var _ BlockingQueue[Delayable] = &TimingWheelQueue[Delayable]{}
+
+// TimingWheelQueue 基于 TimingWheel 的延时队列
+// 和 DelayQueue 一样，出队的元素必然都是已经到期的元素，但是入队和取消的时间复杂度都是 O(1)，
+// 代价是到期时间的精度取决于时间轮的 tick。
+// 它是无界的，到期的元素会按照到期的先后顺序出队
+type TimingWheelQueue[T Delayable] struct {
+	tw  *TimingWheel
+	due *ConcurrentLinkedBlockingQueue[T]
+
+	mutex sync.Mutex
+	// 还没有到期的元素对应的定时器，关闭的时候要把它们从时间轮里面移除
+	timers map[*Timer]struct{}
+	closed bool
+}
+
+// NewTimingWheelQueue 创建一个基于 tw 的延时队列
+// 多个队列可以共享同一个时间轮，关闭队列并不会关闭时间轮
+func NewTimingWheelQueue[T Delayable](tw *TimingWheel) *TimingWheelQueue[T] {
+	return &TimingWheelQueue[T]{
+		tw:     tw,
+		due:    NewConcurrentLinkedBlockingQueue[T](0),
+		timers: make(map[*Timer]struct{}),
+	}
+}
+
+// Enqueue 入队，元素会在 Delay() 之后变得可以出队
+func (q *TimingWheelQueue[T]) Enqueue(ctx context.Context, t T) error {
+	_, err := q.Schedule(ctx, t)
+	return err
+}
+
+// Schedule 入队，并且返回对应的定时器
+// 在元素到期之前调用 Timer.Stop 可以取消该元素
+func (q *TimingWheelQueue[T]) Schedule(ctx context.Context, t T) (*Timer, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	timer := &Timer{tw: q.tw}
+	timer.f = func() {
+		if q.untrack(timer) {
+			// 队列是无界的，所以只有在队列关闭之后才会失败，这时候直接丢弃即可
+			_ = q.due.Enqueue(context.Background(), t)
+		}
+	}
+	timer.onStop = func() {
+		q.untrack(timer)
+	}
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+		return nil, ErrQueueClosed
+	}
+	// 在锁里面放入时间轮，保证 Close 一定能看到这个定时器
+	if q.tw.schedule(timer, t.Delay()) {
+		q.mutex.Unlock()
+		_ = q.due.Enqueue(context.Background(), t)
+		return timer, nil
+	}
+	q.timers[timer] = struct{}{}
+	q.mutex.Unlock()
+	return timer, nil
+}
+
+// Dequeue 取出一个已经到期的元素，没有到期元素的时候会阻塞
+func (q *TimingWheelQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	return q.due.Dequeue(ctx)
+}
+
+// Len 返回已经到期但是还没有出队的元素数量
+func (q *TimingWheelQueue[T]) Len() int {
+	return q.due.Len()
+}
+
+// Close 关闭队列
+// 还没有到期的元素会从时间轮里面移除并被丢弃，已经到期的元素依旧可以出队
+func (q *TimingWheelQueue[T]) Close() error {
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+		return nil
+	}
+	q.closed = true
+	timers := q.timers
+	q.timers = nil
+	q.mutex.Unlock()
+	for timer := range timers {
+		timer.Stop()
+	}
+	return q.due.Close()
+}
+
+// untrack 移除定时器，返回定时器是否还在被跟踪
+func (q *TimingWheelQueue[T]) untrack(timer *Timer) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	_, ok := q.timers[timer]
+	delete(q.timers, timer)
+	return ok
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingWheelQueue(t *testing.T) {
+	t.Parallel()
+	tw, err := NewTimingWheel(time.Millisecond)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, tw.Close())
+	}()
+
+	t.Run("dequeue in order", func(t *testing.T) {
+		q := NewTimingWheelQueue[delayElem](tw)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		now := time.Now()
+		for _, val := range []int{3, 1, 2} {
+			err := q.Enqueue(ctx, delayElem{
+				deadline: now.Add(time.Millisecond * 50 * time.Duration(val)),
+				val:      val,
+			})
+			require.NoError(t, err)
+		}
+		for _, want := range []int{1, 2, 3} {
+			ele, err := q.Dequeue(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, want, ele.val)
+			assert.True(t, ele.Delay() <= 0)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		q := NewTimingWheelQueue[delayElem](tw)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+		defer cancel()
+		timer, err := q.Schedule(ctx, delayElem{
+			deadline: time.Now().Add(time.Millisecond * 50),
+			val:      1,
+		})
+		require.NoError(t, err)
+		assert.True(t, timer.Stop())
+		assert.Empty(t, q.timers)
+		_, err = q.Dequeue(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("invalid context", func(t *testing.T) {
+		q := NewTimingWheelQueue[delayElem](tw)
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		err := q.Enqueue(ctx, delayElem{deadline: time.Now(), val: 1})
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		// 单独的时间轮，这样才能检查定时器有没有被移除
+		tw, err := NewTimingWheel(time.Millisecond)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, tw.Close())
+		}()
+		q := NewTimingWheelQueue[delayElem](tw)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, q.Enqueue(ctx, delayElem{deadline: time.Now(), val: 1}))
+		require.NoError(t, q.Enqueue(ctx, delayElem{deadline: time.Now().Add(time.Minute), val: 2}))
+		assert.Equal(t, 1, q.Len())
+		assert.Equal(t, 1, tw.Len())
+		require.NoError(t, q.Close())
+		assert.Equal(t, 0, tw.Len())
+		require.NoError(t, q.Close())
+		err = q.Enqueue(ctx, delayElem{deadline: time.Now(), val: 3})
+		assert.Equal(t, ErrQueueClosed, err)
+		ele, err := q.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, ele.val)
+		_, err = q.Dequeue(ctx)
+		assert.Equal(t, ErrQueueClosed, err)
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTimingWheel(t *testing.T) {
+	testCases := []struct {
+		name       string
+		tick       time.Duration
+		wheelSizes []int
+		wantErr    error
+		wantLevels int
+	}{
+		{
+			name:       "default wheel sizes",
+			tick:       time.Millisecond,
+			wantLevels: len(defaultWheelSizes),
+		},
+		{
+			name:       "custom wheel sizes",
+			tick:       time.Millisecond,
+			wheelSizes: []int{8, 8},
+			wantLevels: 2,
+		},
+		{
+			name:    "invalid tick",
+			tick:    0,
+			wantErr: errs.NewErrInvalidIntervalValue(0),
+		},
+		{
+			name:       "invalid wheel size",
+			tick:       time.Millisecond,
+			wheelSizes: []int{8, 0},
+			wantErr:    errors.New("ekit: 时间轮的大小必须是正数，实际值 0"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tw, err := NewTimingWheel(tc.tick, tc.wheelSizes...)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			defer func() {
+				require.NoError(t, tw.Close())
+			}()
+			assert.Equal(t, tc.wantLevels, len(tw.levels))
+		})
+	}
+}
+
+func TestTimingWheel_AfterFunc(t *testing.T) {
+	t.Parallel()
+	// 一共只能表示 64 个 tick，所以大部分定时器都需要降级
+	tw, err := NewTimingWheel(time.Millisecond, 4, 4, 4)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, tw.Close())
+	}()
+
+	delays := []time.Duration{
+		-time.Millisecond,
+		0,
+		time.Millisecond * 3,
+		time.Millisecond * 10,
+		time.Millisecond * 50,
+		// 超出了最高层的范围
+		time.Millisecond * 150,
+		time.Millisecond * 300,
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(delays))
+	start := time.Now()
+	for _, d := range delays {
+		d := d
+		tw.AfterFunc(d, func() {
+			defer wg.Done()
+			// 不会提前触发
+			assert.GreaterOrEqual(t, time.Since(start), d)
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, 0, tw.Len())
+}
+
+func TestTimingWheel_Stop(t *testing.T) {
+	t.Parallel()
+	tw, err := NewTimingWheel(time.Millisecond, 8, 8)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, tw.Close())
+	}()
+
+	fired := make(chan struct{}, 2)
+	timer := tw.AfterFunc(time.Millisecond*100, func() {
+		fired <- struct{}{}
+	})
+	tw.AfterFunc(time.Millisecond*200, func() {
+		fired <- struct{}{}
+	})
+	assert.Equal(t, 2, tw.Len())
+	assert.True(t, timer.Stop())
+	// 重复取消
+	assert.False(t, timer.Stop())
+	assert.Equal(t, 1, tw.Len())
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("定时器没有触发")
+	}
+	select {
+	case <-fired:
+		t.Fatal("已经取消的定时器触发了")
+	case <-time.After(time.Millisecond * 100):
+	}
+	// 已经触发的定时器不能取消
+	timer = tw.AfterFunc(0, func() {})
+	assert.False(t, timer.Stop())
+}
+
+func TestTimingWheel_Close(t *testing.T) {
+	t.Parallel()
+	tw, err := NewTimingWheel(time.Millisecond)
+	require.NoError(t, err)
+	fired := make(chan struct{}, 1)
+	tw.AfterFunc(time.Millisecond*50, func() {
+		fired <- struct{}{}
+	})
+	require.NoError(t, tw.Close())
+	require.NoError(t, tw.Close())
+	// 关闭之后添加的定时器永远不会触发
+	timer := tw.AfterFunc(time.Millisecond, func() {
+		fired <- struct{}{}
+	})
+	assert.False(t, timer.Stop())
+	select {
+	case <-fired:
+		t.Fatal("关闭之后定时器触发了")
+	case <-time.After(time.Millisecond * 100):
+	}
+}
+
+func BenchmarkTimingWheel_AfterFunc(b *testing.B) {
+	tw, err := NewTimingWheel(time.Millisecond)
+	require.NoError(b, err)
+	defer func() {
+		_ = tw.Close()
+	}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		timer := tw.AfterFunc(time.Minute, func() {})
+		timer.Stop()
+	}
+}
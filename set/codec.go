@@ -0,0 +1,125 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ecodeclub/ekit/internal/codec"
+)
+
+var errTreeSetNotInitialized = errors.New("ekit: TreeSet 没有初始化，请使用 NewTreeSet 创建之后再解码")
+
+var (
+	_ json.Marshaler   = (*MapSet[int])(nil)
+	_ json.Unmarshaler = (*MapSet[int])(nil)
+	_ json.Marshaler   = (*TreeSet[int])(nil)
+	_ json.Unmarshaler = (*TreeSet[int])(nil)
+)
+
+// MarshalJSON 编码成 JSON 数组，元素的顺序是随机的
+func (s *MapSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Keys())
+}
+
+// UnmarshalJSON 解码 JSON 数组，原有的元素会被清空，重复的元素会被合并
+// 零值的 MapSet 也可以解码
+func (s *MapSet[T]) UnmarshalJSON(data []byte) error {
+	if codec.IsJSONNull(data) {
+		return nil
+	}
+	var vals []T
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return err
+	}
+	s.reset(vals)
+	return nil
+}
+
+// MarshalBinary 使用 gob 编码
+func (s *MapSet[T]) MarshalBinary() ([]byte, error) {
+	return codec.GobEncode(s.Keys())
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的结果，原有的元素会被清空
+func (s *MapSet[T]) UnmarshalBinary(data []byte) error {
+	var vals []T
+	if err := codec.GobDecode(data, &vals); err != nil {
+		return err
+	}
+	s.reset(vals)
+	return nil
+}
+
+func (s *MapSet[T]) reset(vals []T) {
+	s.m = make(map[T]struct{}, len(vals))
+	for _, val := range vals {
+		s.Add(val)
+	}
+}
+
+// MarshalJSON 按照从小到大的顺序编码成 JSON 数组
+func (s *TreeSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.keys())
+}
+
+// UnmarshalJSON 解码 JSON 数组，原有的元素会被清空
+// 因为解码需要 Comparator，所以 s 必须是通过 NewTreeSet 创建的，
+// 零值的 TreeSet 会返回错误
+func (s *TreeSet[T]) UnmarshalJSON(data []byte) error {
+	if codec.IsJSONNull(data) {
+		return nil
+	}
+	var vals []T
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return err
+	}
+	return s.reset(vals)
+}
+
+// MarshalBinary 使用 gob 编码，元素的顺序和 MarshalJSON 一样
+func (s *TreeSet[T]) MarshalBinary() ([]byte, error) {
+	return codec.GobEncode(s.keys())
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的结果，要求和 UnmarshalJSON 一样
+func (s *TreeSet[T]) UnmarshalBinary(data []byte) error {
+	var vals []T
+	if err := codec.GobDecode(data, &vals); err != nil {
+		return err
+	}
+	return s.reset(vals)
+}
+
+func (s *TreeSet[T]) keys() []T {
+	if s.treeMap == nil {
+		return []T{}
+	}
+	return s.treeMap.Keys()
+}
+
+func (s *TreeSet[T]) reset(vals []T) error {
+	if s.treeMap == nil {
+		return errTreeSetNotInitialized
+	}
+	for _, key := range s.treeMap.Keys() {
+		s.Delete(key)
+	}
+	for _, val := range vals {
+		s.Add(val)
+	}
+	return nil
+}
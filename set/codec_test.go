@@ -0,0 +1,93 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/ecodeclub/ekit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapSet_JSON(t *testing.T) {
+	s := NewMapSet[string](0)
+	s.Add("b")
+	s.Add("a")
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	var vals []string
+	require.NoError(t, json.Unmarshal(data, &vals))
+	sort.Strings(vals)
+	assert.Equal(t, []string{"a", "b"}, vals)
+
+	// 零值也可以解码，重复的元素会被合并
+	var decoded MapSet[string]
+	require.NoError(t, json.Unmarshal([]byte(`["a","c","a"]`), &decoded))
+	keys := decoded.Keys()
+	sort.Strings(keys)
+	assert.Equal(t, []string{"a", "c"}, keys)
+	require.NoError(t, json.Unmarshal([]byte(`null`), &decoded))
+	assert.Equal(t, 2, len(decoded.Keys()))
+	var typeErr *json.UnmarshalTypeError
+	assert.ErrorAs(t, json.Unmarshal([]byte(`{"a":1}`), &decoded), &typeErr)
+
+	bin, err := s.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, decoded.UnmarshalBinary(bin))
+	keys = decoded.Keys()
+	sort.Strings(keys)
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.Error(t, decoded.UnmarshalBinary([]byte("invalid")))
+}
+
+func TestTreeSet_JSON(t *testing.T) {
+	s, err := NewTreeSet[int](ekit.ComparatorRealNumber[int])
+	require.NoError(t, err)
+	for _, v := range []int{3, 1, 2} {
+		s.Add(v)
+	}
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.Equal(t, `[1,2,3]`, string(data))
+
+	// 降序的 Comparator 在解码之后依旧生效
+	decoded, err := NewTreeSet[int](func(src, dst int) int {
+		return dst - src
+	})
+	require.NoError(t, err)
+	decoded.Add(100)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, []int{3, 2, 1}, decoded.Keys())
+	require.NoError(t, json.Unmarshal([]byte(`null`), decoded))
+	assert.Equal(t, []int{3, 2, 1}, decoded.Keys())
+	var typeErr *json.UnmarshalTypeError
+	assert.ErrorAs(t, json.Unmarshal([]byte(`{"a":1}`), decoded), &typeErr)
+
+	var zero TreeSet[int]
+	assert.Equal(t, errTreeSetNotInitialized, json.Unmarshal(data, &zero))
+	data, err = json.Marshal(&zero)
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(data))
+
+	bin, err := s.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, decoded.UnmarshalBinary(bin))
+	assert.Equal(t, []int{3, 2, 1}, decoded.Keys())
+	assert.Equal(t, errTreeSetNotInitialized, zero.UnmarshalBinary(bin))
+	assert.Error(t, decoded.UnmarshalBinary([]byte("invalid")))
+}
@@ -0,0 +1,313 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncx
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+
+	"github.com/ecodeclub/ekit/bean/option"
+	"github.com/ecodeclub/ekit/internal/hashx"
+)
+
+const defaultShardCount = 32
+
+var shardedMapSeed = maphash.MakeSeed()
+
+// ShardedMap 分片的并发安全的 map
+// 键值对按照哈希值分散到多个分片上，每个分片是一个用读写锁保护的 map，
+// 不同分片上的操作互不影响，所以在写多的场景下比 Map 的性能要好得多。
+// 默认的哈希函数支持字符串、整数、浮点数、复数、bool、指针和 channel，
+// 结构体、数组和接口等其它类型必须通过 ShardedMapWithHasher 指定哈希函数。
+// 注意，哈希函数必须保证相等的 key 返回同样的哈希值
+type ShardedMap[K comparable, V any] struct {
+	shards []*mapShard[K, V]
+	// mask 分片数量总是 2 的幂，所以可以用位运算代替取模
+	mask   uint64
+	hasher func(key K) uint64
+}
+
+type mapShard[K comparable, V any] struct {
+	mutex sync.RWMutex
+	m     map[K]V
+}
+
+// NewShardedMap 创建一个 ShardedMap，默认有 32 个分片
+// 如果 K 没有默认的哈希函数，并且没有通过 ShardedMapWithHasher 指定，那么会返回 error
+func NewShardedMap[K comparable, V any](opts ...option.Option[ShardedMap[K, V]]) (*ShardedMap[K, V], error) {
+	res := &ShardedMap[K, V]{
+		shards: make([]*mapShard[K, V], defaultShardCount),
+	}
+	option.Apply(res, opts...)
+	if res.hasher == nil {
+		hasher, ok := defaultHasher[K]()
+		if !ok {
+			var zero K
+			return nil, fmt.Errorf("ekit: 类型 %s 没有默认的哈希函数，请使用 ShardedMapWithHasher 指定", reflect.TypeOf(&zero).Elem())
+		}
+		res.hasher = hasher
+	}
+	for i := range res.shards {
+		res.shards[i] = &mapShard[K, V]{m: make(map[K]V)}
+	}
+	res.mask = uint64(len(res.shards) - 1)
+	return res, nil
+}
+
+// ShardedMapWithShardCount 指定分片的数量，会向上取整到 2 的幂，小于等于 0 的时候使用默认值
+func ShardedMapWithShardCount[K comparable, V any](count int) option.Option[ShardedMap[K, V]] {
+	return func(m *ShardedMap[K, V]) {
+		if count <= 0 {
+			return
+		}
+		n := 1
+		for n < count {
+			n <<= 1
+		}
+		m.shards = make([]*mapShard[K, V], n)
+	}
+}
+
+// ShardedMapWithHasher 指定哈希函数，相等的 key 必须返回同样的哈希值
+func ShardedMapWithHasher[K comparable, V any](hasher func(key K) uint64) option.Option[ShardedMap[K, V]] {
+	return func(m *ShardedMap[K, V]) {
+		m.hasher = hasher
+	}
+}
+
+// Load 加载键值对
+func (m *ShardedMap[K, V]) Load(key K) (V, bool) {
+	s := m.shard(key)
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	val, ok := s.m[key]
+	return val, ok
+}
+
+// Store 存储键值对
+func (m *ShardedMap[K, V]) Store(key K, val V) {
+	s := m.shard(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.m[key] = val
+}
+
+// LoadOrStore 加载或者存储一个键值对
+// true 代表是加载的，false 代表执行了 store
+func (m *ShardedMap[K, V]) LoadOrStore(key K, val V) (V, bool) {
+	s := m.shard(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if actual, ok := s.m[key]; ok {
+		return actual, true
+	}
+	s.m[key] = val
+	return val, false
+}
+
+// LoadAndDelete 加载并且删除一个键值对
+func (m *ShardedMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	s := m.shard(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	val, ok := s.m[key]
+	if ok {
+		delete(s.m, key)
+	}
+	return val, ok
+}
+
+// Delete 删除键值对
+func (m *ShardedMap[K, V]) Delete(key K) {
+	s := m.shard(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.m, key)
+}
+
+// Compute 原子地计算 key 对应的新值
+// fn 的参数是旧值以及 key 是否存在，返回新值以及是否保留，
+// 如果 keep 为 false，那么 key 会被删除。
+// 返回值是最终的值以及 key 是否存在。
+// fn 是在锁范围内执行的，所以 fn 里面不能再访问 ShardedMap
+func (m *ShardedMap[K, V]) Compute(key K, fn func(val V, ok bool) (newVal V, keep bool)) (V, bool) {
+	s := m.shard(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	old, ok := s.m[key]
+	val, keep := fn(old, ok)
+	if !keep {
+		delete(s.m, key)
+		var zero V
+		return zero, false
+	}
+	s.m[key] = val
+	return val, true
+}
+
+// ComputeIfAbsent 如果 key 不存在，那么调用 fn 计算并且存储新值，否则直接返回旧值
+// 和 Map.LoadOrStoreFunc 不同，fn 只会被调用一次。
+// fn 返回 error 的时候不会存储任何值。
+// fn 是在锁范围内执行的，所以 fn 里面不能再访问 ShardedMap
+func (m *ShardedMap[K, V]) ComputeIfAbsent(key K, fn func(key K) (V, error)) (V, error) {
+	s := m.shard(key)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if val, ok := s.m[key]; ok {
+		return val, nil
+	}
+	val, err := fn(key)
+	if err != nil {
+		return val, err
+	}
+	s.m[key] = val
+	return val, nil
+}
+
+// Len 返回键值对的数量
+// 每个分片是分别加锁统计的，所以并发修改的时候返回的只是一个近似值
+func (m *ShardedMap[K, V]) Len() int {
+	res := 0
+	for _, s := range m.shards {
+		s.mutex.RLock()
+		res += len(s.m)
+		s.mutex.RUnlock()
+	}
+	return res
+}
+
+// Range 遍历，如果 f 返回 false，那么就会中断遍历
+// 遍历的是每个分片的快照，所以 f 里面可以修改 ShardedMap，
+// 但是这些修改不一定能够在本次遍历中体现出来
+func (m *ShardedMap[K, V]) Range(f func(key K, val V) bool) {
+	for _, s := range m.shards {
+		for _, kv := range s.snapshot() {
+			if !f(kv.key, kv.val) {
+				return
+			}
+		}
+	}
+}
+
+// Keys 返回所有的键，顺序是随机的
+func (m *ShardedMap[K, V]) Keys() []K {
+	res := make([]K, 0, m.Len())
+	m.Range(func(key K, val V) bool {
+		res = append(res, key)
+		return true
+	})
+	return res
+}
+
+// Values 返回所有的值，顺序是随机的
+func (m *ShardedMap[K, V]) Values() []V {
+	res := make([]V, 0, m.Len())
+	m.Range(func(key K, val V) bool {
+		res = append(res, val)
+		return true
+	})
+	return res
+}
+
+func (m *ShardedMap[K, V]) shard(key K) *mapShard[K, V] {
+	return m.shards[m.hasher(key)&m.mask]
+}
+
+type shardEntry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+func (s *mapShard[K, V]) snapshot() []shardEntry[K, V] {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	res := make([]shardEntry[K, V], 0, len(s.m))
+	for k, v := range s.m {
+		res = append(res, shardEntry[K, V]{key: k, val: v})
+	}
+	return res
+}
+
+// defaultHasher 根据 K 的类型选择默认的哈希函数，相等的 key 总是返回同样的哈希值
+// 指针、channel 使用地址计算哈希值；其余没有办法安全计算哈希值的类型返回 false
+func defaultHasher[K comparable]() (func(key K) uint64, bool) {
+	var zero K
+	// 常见的类型避免反射
+	switch any(zero).(type) {
+	case string:
+		return func(key K) uint64 {
+			return maphash.String(shardedMapSeed, any(key).(string))
+		}, true
+	case int:
+		return func(key K) uint64 {
+			return hashx.Mix64(uint64(any(key).(int)))
+		}, true
+	case int64:
+		return func(key K) uint64 {
+			return hashx.Mix64(uint64(any(key).(int64)))
+		}, true
+	case uint64:
+		return func(key K) uint64 {
+			return hashx.Mix64(any(key).(uint64))
+		}, true
+	}
+	switch reflect.TypeOf(&zero).Elem().Kind() {
+	case reflect.String:
+		return func(key K) uint64 {
+			return maphash.String(shardedMapSeed, reflect.ValueOf(key).String())
+		}, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(key K) uint64 {
+			return hashx.Mix64(uint64(reflect.ValueOf(key).Int()))
+		}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(key K) uint64 {
+			return hashx.Mix64(reflect.ValueOf(key).Uint())
+		}, true
+	case reflect.Bool:
+		return func(key K) uint64 {
+			if reflect.ValueOf(key).Bool() {
+				return 1
+			}
+			return 0
+		}, true
+	case reflect.Float32, reflect.Float64:
+		return func(key K) uint64 {
+			return floatHash(reflect.ValueOf(key).Float())
+		}, true
+	case reflect.Complex64, reflect.Complex128:
+		return func(key K) uint64 {
+			c := reflect.ValueOf(key).Complex()
+			return hashx.Mix64(floatHash(real(c))*31 + floatHash(imag(c)))
+		}, true
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return func(key K) uint64 {
+			return hashx.Mix64(uint64(reflect.ValueOf(key).Pointer()))
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// floatHash -0 和 0 是相等的，所以要先统一成 0
+func floatHash(f float64) uint64 {
+	if f == 0 {
+		f = 0
+	}
+	return hashx.Mix64(math.Float64bits(f))
+}
@@ -0,0 +1,438 @@
+// Copyright 2021 ecodeclub
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncx
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ecodeclub/ekit/bean/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShardedMap(t *testing.T) {
+	testCases := []struct {
+		name      string
+		count     int
+		wantCount int
+	}{
+		{
+			name:      "default",
+			count:     0,
+			wantCount: defaultShardCount,
+		},
+		{
+			name:      "negative",
+			count:     -1,
+			wantCount: defaultShardCount,
+		},
+		{
+			name:      "power of 2",
+			count:     8,
+			wantCount: 8,
+		},
+		{
+			name:      "round up",
+			count:     9,
+			wantCount: 16,
+		},
+		{
+			name:      "one shard",
+			count:     1,
+			wantCount: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newShardedMap[string, int](t, ShardedMapWithShardCount[string, int](tc.count))
+			assert.Equal(t, tc.wantCount, len(m.shards))
+			assert.Equal(t, uint64(tc.wantCount-1), m.mask)
+			for i := 0; i < 100; i++ {
+				m.Store(strconv.Itoa(i), i)
+			}
+			assert.Equal(t, 100, m.Len())
+		})
+	}
+}
+
+func TestShardedMap_LoadAndStore(t *testing.T) {
+	m := newShardedMap[string, *User](t)
+	_, ok := m.Load("tom")
+	assert.False(t, ok)
+
+	m.Store("tom", &User{Name: "Tom"})
+	val, ok := m.Load("tom")
+	assert.True(t, ok)
+	assert.Equal(t, &User{Name: "Tom"}, val)
+
+	// 值为 nil 和 key 不存在是两码事
+	m.Store("nil", nil)
+	val, ok = m.Load("nil")
+	assert.True(t, ok)
+	assert.Nil(t, val)
+
+	val, loaded := m.LoadOrStore("tom", &User{Name: "Jerry"})
+	assert.True(t, loaded)
+	assert.Equal(t, &User{Name: "Tom"}, val)
+	val, loaded = m.LoadOrStore("jerry", &User{Name: "Jerry"})
+	assert.False(t, loaded)
+	assert.Equal(t, &User{Name: "Jerry"}, val)
+	assert.Equal(t, 3, m.Len())
+
+	val, loaded = m.LoadAndDelete("tom")
+	assert.True(t, loaded)
+	assert.Equal(t, &User{Name: "Tom"}, val)
+	_, loaded = m.LoadAndDelete("tom")
+	assert.False(t, loaded)
+
+	m.Delete("jerry")
+	m.Delete("not exist")
+	_, ok = m.Load("jerry")
+	assert.False(t, ok)
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestShardedMap_Compute(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     string
+		fn      func(val int, ok bool) (int, bool)
+		wantVal int
+		wantOk  bool
+		wantLen int
+	}{
+		{
+			name: "update",
+			key:  "a",
+			fn: func(val int, ok bool) (int, bool) {
+				assert.True(t, ok)
+				return val + 1, true
+			},
+			wantVal: 2,
+			wantOk:  true,
+			wantLen: 1,
+		},
+		{
+			name: "insert",
+			key:  "b",
+			fn: func(val int, ok bool) (int, bool) {
+				assert.False(t, ok)
+				return 10, true
+			},
+			wantVal: 10,
+			wantOk:  true,
+			wantLen: 2,
+		},
+		{
+			name: "delete",
+			key:  "a",
+			fn: func(val int, ok bool) (int, bool) {
+				return 0, false
+			},
+			wantLen: 0,
+		},
+		{
+			name: "not exist and not keep",
+			key:  "b",
+			fn: func(val int, ok bool) (int, bool) {
+				return 10, false
+			},
+			wantLen: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newShardedMap[string, int](t)
+			m.Store("a", 1)
+			val, ok := m.Compute(tc.key, tc.fn)
+			assert.Equal(t, tc.wantVal, val)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantLen, m.Len())
+			val, ok = m.Load(tc.key)
+			assert.Equal(t, tc.wantVal, val)
+			assert.Equal(t, tc.wantOk, ok)
+		})
+	}
+}
+
+func TestShardedMap_ComputeIfAbsent(t *testing.T) {
+	m := newShardedMap[string, int](t)
+	m.Store("a", 1)
+	cnt := 0
+	fn := func(key string) (int, error) {
+		cnt++
+		return len(key), nil
+	}
+	val, err := m.ComputeIfAbsent("a", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.Equal(t, 0, cnt)
+
+	val, err = m.ComputeIfAbsent("hello", fn)
+	require.NoError(t, err)
+	assert.Equal(t, 5, val)
+	assert.Equal(t, 1, cnt)
+
+	_, err = m.ComputeIfAbsent("error", func(key string) (int, error) {
+		return 0, errors.New("mock error")
+	})
+	assert.Equal(t, errors.New("mock error"), err)
+	_, ok := m.Load("error")
+	assert.False(t, ok)
+}
+
+func TestShardedMap_Range(t *testing.T) {
+	m := newShardedMap[int, int](t, ShardedMapWithShardCount[int, int](4))
+	for i := 0; i < 100; i++ {
+		m.Store(i, i*10)
+	}
+	keys := m.Keys()
+	sort.Ints(keys)
+	vals := m.Values()
+	sort.Ints(vals)
+	wantKeys := make([]int, 0, 100)
+	wantVals := make([]int, 0, 100)
+	for i := 0; i < 100; i++ {
+		wantKeys = append(wantKeys, i)
+		wantVals = append(wantVals, i*10)
+	}
+	assert.Equal(t, wantKeys, keys)
+	assert.Equal(t, wantVals, vals)
+
+	// 遍历的是快照，所以可以在遍历的过程中修改
+	cnt := 0
+	m.Range(func(key int, val int) bool {
+		assert.Equal(t, key*10, val)
+		m.Delete(key)
+		cnt++
+		return cnt < 50
+	})
+	assert.Equal(t, 50, cnt)
+	assert.Equal(t, 50, m.Len())
+}
+
+func TestShardedMap_Hasher(t *testing.T) {
+	type key struct {
+		id   int
+		name string
+	}
+	t.Run("no default hasher", func(t *testing.T) {
+		_, err := NewShardedMap[key, int]()
+		assert.EqualError(t, err, "ekit: 类型 syncx.key 没有默认的哈希函数，请使用 ShardedMapWithHasher 指定")
+		_, err = NewShardedMap[any, int]()
+		assert.EqualError(t, err, "ekit: 类型 interface {} 没有默认的哈希函数，请使用 ShardedMapWithHasher 指定")
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		// 指针按照地址计算哈希值，修改指向的对象不影响查找
+		m := newShardedMap[*User, int](t)
+		u1, u2 := &User{Name: "Tom"}, &User{Name: "Tom"}
+		m.Store(u1, 1)
+		m.Store(u2, 2)
+		u1.Name = "Jerry"
+		val, ok := m.Load(u1)
+		assert.True(t, ok)
+		assert.Equal(t, 1, val)
+		val, ok = m.Load(u2)
+		assert.True(t, ok)
+		assert.Equal(t, 2, val)
+		assert.Equal(t, 2, m.Len())
+	})
+
+	t.Run("chan", func(t *testing.T) {
+		m := newShardedMap[chan int, int](t)
+		ch := make(chan int)
+		m.Store(ch, 1)
+		val, ok := m.Load(ch)
+		assert.True(t, ok)
+		assert.Equal(t, 1, val)
+		_, ok = m.Load(make(chan int))
+		assert.False(t, ok)
+	})
+
+	t.Run("float", func(t *testing.T) {
+		m := newShardedMap[float64, int](t, ShardedMapWithShardCount[float64, int](1024))
+		negZero := math.Copysign(0, -1)
+		m.Store(0.0, 1)
+		m.Store(negZero, 2)
+		val, ok := m.Load(0.0)
+		assert.True(t, ok)
+		assert.Equal(t, 2, val)
+		assert.Equal(t, 1, m.Len())
+		m.Store(1.5, 3)
+		val, ok = m.Load(1.5)
+		assert.True(t, ok)
+		assert.Equal(t, 3, val)
+
+		f32 := newShardedMap[float32, int](t, ShardedMapWithShardCount[float32, int](1024))
+		f32.Store(float32(negZero), 1)
+		_, ok = f32.Load(0)
+		assert.True(t, ok)
+	})
+
+	t.Run("complex", func(t *testing.T) {
+		m := newShardedMap[complex128, int](t, ShardedMapWithShardCount[complex128, int](1024))
+		m.Store(complex(0, 1), 1)
+		m.Store(complex(math.Copysign(0, -1), 1), 2)
+		assert.Equal(t, 1, m.Len())
+	})
+
+	t.Run("named types", func(t *testing.T) {
+		type name string
+		type id uint16
+		names := newShardedMap[name, int](t)
+		names.Store("tom", 1)
+		val, ok := names.Load("tom")
+		assert.True(t, ok)
+		assert.Equal(t, 1, val)
+		ids := newShardedMap[id, bool](t)
+		ids.Store(1, true)
+		_, ok = ids.Load(1)
+		assert.True(t, ok)
+		bools := newShardedMap[bool, int](t)
+		bools.Store(true, 1)
+		bools.Store(false, 0)
+		assert.Equal(t, 2, bools.Len())
+	})
+
+	t.Run("custom hasher", func(t *testing.T) {
+		m := newShardedMap[key, int](t, ShardedMapWithHasher[key, int](func(k key) uint64 {
+			return uint64(k.id)
+		}), ShardedMapWithShardCount[key, int](4))
+		for i := 0; i < 8; i++ {
+			m.Store(key{id: i}, i)
+		}
+		for i, s := range m.shards {
+			assert.Equal(t, 2, len(s.m), "shard %d", i)
+		}
+	})
+
+	t.Run("spread", func(t *testing.T) {
+		// 连续的整数应该比较均匀地分散在各个分片上
+		m := newShardedMap[int, int](t)
+		for i := 0; i < 32*100; i++ {
+			m.Store(i, i)
+		}
+		for _, s := range m.shards {
+			assert.Greater(t, len(s.m), 50)
+		}
+	})
+}
+
+func TestShardedMap_Concurrent(t *testing.T) {
+	m := newShardedMap[string, int](t)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				m.Compute(strconv.Itoa(j%100), func(val int, ok bool) (int, bool) {
+					return val + 1, true
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 100, m.Len())
+	m.Range(func(key string, val int) bool {
+		assert.Equal(t, 100, val)
+		return true
+	})
+}
+
+// 只读的时候两者差不多，写得越多 ShardedMap 的优势越明显
+// goos: linux
+// goarch: amd64
+// pkg: github.com/ecodeclub/ekit/syncx
+// cpu: Intel(R) Xeon(R) Processor
+// BenchmarkShardedMap/read_only/ShardedMap         	 4957431	        52.71 ns/op
+// BenchmarkShardedMap/read_only/Map                	 4601332	        54.90 ns/op
+// BenchmarkShardedMap/read_mostly/ShardedMap       	 3608230	        56.50 ns/op
+// BenchmarkShardedMap/read_mostly/Map              	 2855413	        82.68 ns/op
+// BenchmarkShardedMap/write_heavy/ShardedMap       	 3306348	        67.98 ns/op
+// BenchmarkShardedMap/write_heavy/Map              	 1591056	       148.1 ns/op
+// BenchmarkShardedMap/write_only/ShardedMap        	 3043129	        75.28 ns/op
+// BenchmarkShardedMap/write_only/Map               	 1000000	       204.6 ns/op
+func BenchmarkShardedMap(b *testing.B) {
+	const keyCount = 1024
+	keys := make([]string, keyCount)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	testCases := []struct {
+		name string
+		// writePercent 写操作的百分比
+		writePercent int
+	}{
+		{name: "read only", writePercent: 0},
+		{name: "read mostly", writePercent: 10},
+		{name: "write heavy", writePercent: 50},
+		{name: "write only", writePercent: 100},
+	}
+	for _, tc := range testCases {
+		b.Run(tc.name, func(b *testing.B) {
+			b.Run("ShardedMap", func(b *testing.B) {
+				m := newShardedMap[string, int](b)
+				for i, k := range keys {
+					m.Store(k, i)
+				}
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						key := keys[i%keyCount]
+						if i%100 < tc.writePercent {
+							m.Store(key, i)
+						} else {
+							m.Load(key)
+						}
+						i++
+					}
+				})
+			})
+			b.Run("Map", func(b *testing.B) {
+				var m Map[string, int]
+				for i, k := range keys {
+					m.Store(k, i)
+				}
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						key := keys[i%keyCount]
+						if i%100 < tc.writePercent {
+							m.Store(key, i)
+						} else {
+							m.Load(key)
+						}
+						i++
+					}
+				})
+			})
+		})
+	}
+}
+
+func newShardedMap[K comparable, V any](t testing.TB, opts ...option.Option[ShardedMap[K, V]]) *ShardedMap[K, V] {
+	m, err := NewShardedMap[K, V](opts...)
+	require.NoError(t, err)
+	return m
+}